@@ -2,8 +2,41 @@ package domain
 
 import "time"
 
+// Session - sign in of the user, lives while its refresh token is rotated
 type Session struct {
-	UserId      string
-	AccessToken string
-	ExpiresAt   time.Time
+	Id        string    `json:"id" db:"id"`
+	UserId    string    `json:"-" db:"user_id"`
+	UserAgent string    `json:"user_agent" db:"user_agent"` // of the last sign in or refresh
+	IP        string    `json:"ip" db:"ip"`
+	Current   bool      `json:"current" db:"-"` // the session of the request
+	CreatedAt time.Time `json:"created" db:"created_at"`
+	ExpiresAt time.Time `json:"expires" db:"expires_at"` // prolonged on every refresh
+}
+
+// Client - client signing in or refreshing the session
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// SessionTokens - tokens issued to the session on the sign in and on every refresh,
+// the refresh token is stored as its hash
+type SessionTokens struct {
+	AccessTokenHash  string
+	AccessExpiresAt  time.Time
+	RefreshTokenHash string
+	ExpiresAt        time.Time // new expiration of the session
+}
+
+// RevokedToken - access token replaced on the refresh, it's rejected until it expires
+type RevokedToken struct {
+	TokenHash string    `db:"token"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// Tokens - tokens returned to the user
+type Tokens struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
 }
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Cursor - position in a listing after the last returned document: values of its sort fields and its id.
+// The cursor is valid only with the sort it was made for
+type Cursor struct {
+	Sort   string   `json:"s,omitempty"`
+	Values []string `json:"v"`
+	Id     string   `json:"id"`
+}
+
+// NewCursor - returns the opaque cursor pointing after the document, sort is the requested sort
+// and fields are the fields the listing is actually ordered by
+func NewCursor(sort []Sort, fields []Sort, document *Document) string {
+	cursor := Cursor{
+		Sort:   SortString(sort),
+		Values: make([]string, 0, len(fields)),
+		Id:     document.Id,
+	}
+
+	for _, field := range fields {
+		cursor.Values = append(cursor.Values, document.SortValue(field.Field))
+	}
+
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor - parses the opaque cursor
+func ParseCursor(cursor string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || !uuidRegexp.MatchString(c.Id) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// SortString - formats the sort like the sort query parameter
+func SortString(sort []Sort) string {
+	fields := make([]string, 0, len(sort))
+	for _, s := range sort {
+		if s.Desc {
+			fields = append(fields, "-"+s.Field)
+		} else {
+			fields = append(fields, s.Field)
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// SetCursor - continues the listing from the cursor, the page offset is ignored then.
+// Must be called after SetQuery, the cursor is checked against the sort
+func (p *FilterParams) SetCursor(cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+
+	c, err := ParseCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	if c.Sort != SortString(p.Sort) {
+		return ErrInvalidCursor
+	}
+
+	p.Cursor = c
+	p.Offset = 0
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const cursorDocumentId = "0b6f1f5e-4a4f-4c1e-9d55-1f0e8b3c2a10"
+
+func encodeCursor(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestParseCursor(t *testing.T) {
+	document := &Document{
+		Id:        cursorDocumentId,
+		Name:      "report",
+		Version:   3,
+		CreatedAt: time.Date(2024, 1, 31, 10, 0, 0, 123, time.UTC),
+	}
+	sort := []Sort{{Field: "name", Desc: true}, {Field: "version"}}
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   Cursor
+		err    error
+	}{
+		{
+			name:   "made by NewCursor",
+			cursor: NewCursor(sort, sort, document),
+			want:   Cursor{Sort: "-name,version", Values: []string{"report", "3"}, Id: cursorDocumentId},
+		},
+		{
+			name:   "default sort",
+			cursor: NewCursor(nil, []Sort{{Field: "created_at"}}, document),
+			want:   Cursor{Values: []string{"2024-01-31T10:00:00.000000123Z"}, Id: cursorDocumentId},
+		},
+		{name: "empty", cursor: "", err: ErrInvalidCursor},
+		{name: "not base64", cursor: "not a cursor!", err: ErrInvalidCursor},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"v":[],"id":"` + cursorDocumentId + `"}`)), err: ErrInvalidCursor},
+		{name: "not JSON", cursor: encodeCursor("name=report"), err: ErrInvalidCursor},
+		{name: "values of wrong type", cursor: encodeCursor(`{"v":[1],"id":"` + cursorDocumentId + `"}`), err: ErrInvalidCursor},
+		{name: "no id", cursor: encodeCursor(`{"v":["report"]}`), err: ErrInvalidCursor},
+		{name: "id isn't a UUID", cursor: encodeCursor(`{"v":[],"id":"1' OR '1'='1"}`), err: ErrInvalidCursor},
+		{name: "uppercase id", cursor: encodeCursor(`{"v":[],"id":"0B6F1F5E-4A4F-4C1E-9D55-1F0E8B3C2A10"}`), err: ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCursor(tt.cursor)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseCursor(%q) error = %v, want %v", tt.cursor, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCursor(%q) = %+v, want %+v", tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCursor(t *testing.T) {
+	document := &Document{Id: cursorDocumentId, Name: "report", Version: 3}
+
+	tests := []struct {
+		name   string
+		sort   []Sort // sort of the request
+		cursor string
+		want   Cursor
+		offset int
+		err    error
+	}{
+		{name: "no cursor", sort: []Sort{{Field: "name"}}, cursor: "", offset: 20},
+		{
+			name:   "same sort",
+			sort:   []Sort{{Field: "name"}},
+			cursor: NewCursor([]Sort{{Field: "name"}}, []Sort{{Field: "name"}}, document),
+			want:   Cursor{Sort: "name", Values: []string{"report"}, Id: cursorDocumentId},
+		},
+		{
+			name:   "default sort",
+			cursor: NewCursor(nil, []Sort{{Field: "name"}}, document),
+			want:   Cursor{Values: []string{"report"}, Id: cursorDocumentId},
+		},
+		{
+			name:   "other direction",
+			sort:   []Sort{{Field: "name"}},
+			cursor: NewCursor([]Sort{{Field: "name", Desc: true}}, []Sort{{Field: "name"}}, document),
+			offset: 20,
+			err:    ErrInvalidCursor,
+		},
+		{
+			name:   "other field",
+			sort:   []Sort{{Field: "version"}},
+			cursor: NewCursor([]Sort{{Field: "name"}}, []Sort{{Field: "name"}}, document),
+			offset: 20,
+			err:    ErrInvalidCursor,
+		},
+		{
+			name:   "cursor of the default sort with a sort",
+			sort:   []Sort{{Field: "name"}},
+			cursor: NewCursor(nil, []Sort{{Field: "name"}}, document),
+			offset: 20,
+			err:    ErrInvalidCursor,
+		},
+		{name: "tampered", sort: []Sort{{Field: "name"}}, cursor: "eyJ2IjpbXX0", offset: 20, err: ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := FilterParams{Sort: tt.sort, Offset: 20}
+			if err := params.SetCursor(tt.cursor); !errors.Is(err, tt.err) {
+				t.Fatalf("SetCursor() error = %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(params.Cursor, tt.want) {
+				t.Errorf("SetCursor() cursor = %+v, want %+v", params.Cursor, tt.want)
+			}
+
+			if params.Offset != tt.offset {
+				t.Errorf("SetCursor() offset = %v, want %v", params.Offset, tt.offset)
+			}
+		})
+	}
+}
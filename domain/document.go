@@ -1,15 +1,95 @@
 package domain
 
-import "time"
+import (
+	"io"
+	"strconv"
+	"time"
+)
 
 type Document struct {
-	Id           string `json:"id" db:"id"`
-	Name         string `json:"name" db:"name"`
-	Mime         string `json:"mime" db:"mime"`
-	FilePath     string `db:"file_path"`
-	IsFile       bool   `json:"is_file" db:"is_file"`
-	IsPublic     bool   `json:"is_public" db:"is_public"`
-	DocumentData string `json:"json,omitempty" db:"document_data"`
-	Grants       []string
+	Id           string  `json:"id" db:"id"`
+	Name         string  `json:"name" db:"name"`
+	Mime         string  `json:"mime" db:"mime"`
+	MimeMismatch bool    `json:"mime_mismatch" db:"mime_mismatch"` // the passed mime contradicts the file content
+	FilePath     string  `json:"-" db:"file_path"`                 // opaque storage key
+	IsFile       bool    `json:"is_file" db:"is_file"`
+	IsPublic     bool    `json:"is_public" db:"is_public"`
+	DocumentData string  `json:"json,omitempty" db:"document_data"`
+	Type         string  `json:"type,omitempty" db:"type"` // JSON schema of DocumentData
+	Version      int     `json:"version" db:"version"`
+	FolderId     *string `json:"folder_id" db:"folder_id"` // nil - root
+	Grants       []Grant
 	CreatedAt    time.Time `json:"created" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated" db:"updated_at"`
+}
+
+// DocumentVersion - revision of the document content
+type DocumentVersion struct {
+	Version      int       `json:"version" db:"version"`
+	Mime         string    `json:"mime" db:"mime"`
+	MimeMismatch bool      `json:"mime_mismatch" db:"mime_mismatch"`
+	FilePath     string    `json:"-" db:"file_path"` // opaque storage key
+	IsFile       bool      `json:"is_file" db:"is_file"`
+	DocumentData string    `json:"json,omitempty" db:"document_data"`
+	Current      bool      `json:"current" db:"current"`
+	CreatedAt    time.Time `json:"created" db:"created_at"`
+}
+
+// DocumentUpdate - document metadata changes, nil fields are left unchanged
+type DocumentUpdate struct {
+	Name         *string
+	Mime         *string
+	MimeMismatch *bool // set by the service for files
+	IsPublic     *bool
+	Grants       *[]Grant
+	DocumentData *string // empty - remove the data
+	Type         *string // empty - remove the schema
+	FolderId     *string // empty - move to the root
+}
+
+// File - uploaded file content
+type File struct {
+	Name    string
+	Size    int64
+	Content io.ReadSeeker
+}
+
+// DocumentContent - file of the document waiting for the text extraction
+type DocumentContent struct {
+	Id       string `db:"id"`
+	Name     string `db:"name"`
+	Mime     string `db:"mime"`
+	FilePath string `db:"file_path"`
+}
+
+// DocumentList - page of a document listing
+type DocumentList struct {
+	Documents  *[]Document
+	NextCursor string // empty - the last page
+	HasMore    bool
+	Total      *int // nil - the count isn't requested
+}
+
+// SortValue - value of the sort field of the document, see DocumentFields
+func (d *Document) SortValue(field string) string {
+	switch field {
+	case "name":
+		return d.Name
+	case "mime":
+		return d.Mime
+	case "mime_mismatch":
+		return strconv.FormatBool(d.MimeMismatch)
+	case "is_file":
+		return strconv.FormatBool(d.IsFile)
+	case "is_public":
+		return strconv.FormatBool(d.IsPublic)
+	case "version":
+		return strconv.Itoa(d.Version)
+	case "created_at":
+		return d.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return d.UpdatedAt.Format(time.RFC3339Nano)
+	}
+
+	return ""
 }
@@ -19,5 +19,48 @@ var (
 	ErrParameterIsEmpty        = errors.New("parameter is empty")
 	ErrDocumentNotFound        = errors.New("document not found")
 	ErrFileIsDamagedOrNotFound = errors.New("file is damaged or not found")
-	ErrFileThisNameIsAlready   = errors.New("file this name is already")
+	ErrDocumentIsNotFile       = errors.New("document is not a file")
+	ErrNothingToUpdate         = errors.New("nothing to update")
+	ErrInvalidVersion          = errors.New("invalid version")
+	ErrVersionNotFound         = errors.New("version not found")
+	ErrLoginsIsEmpty           = errors.New("logins is empty")
+	ErrInvalidPermission       = errors.New("invalid permission")
+	ErrPermissionDenied        = errors.New("permission denied")
+	ErrShareLinkNotFound       = errors.New("share link not found")
+	ErrShareLinkExpired        = errors.New("share link expired")
+	ErrInvalidExpiration       = errors.New("invalid expiration")
+	ErrInvalidMaxDownloads     = errors.New("invalid max downloads")
+	ErrInvalidGroupName        = errors.New("invalid group name")
+	ErrGroupNameIsBusy         = errors.New("group name is busy")
+	ErrGroupNotFound           = errors.New("group not found")
+	ErrFolderNotFound          = errors.New("folder not found")
+	ErrFolderIsNotEmpty        = errors.New("folder is not empty")
+	ErrFolderCycle             = errors.New("folder can't be moved into itself")
+	ErrQueryIsEmpty            = errors.New("query is empty")
+	ErrInvalidFilter           = errors.New("invalid filter")
+	ErrInvalidSort             = errors.New("invalid sort")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidJSON             = errors.New("json is invalid")
+	ErrInvalidSchema           = errors.New("invalid json schema")
+	ErrInvalidSchemaName       = errors.New("invalid schema name")
+	ErrSchemaNameIsBusy        = errors.New("schema name is busy")
+	ErrSchemaNotFound          = errors.New("schema not found")
+	ErrSchemaIsUsed            = errors.New("schema is used by documents")
+	ErrDocumentDataIsInvalid   = errors.New("json doesn't match the schema")
+	ErrUnsupportedPatch        = errors.New("unsupported patch type")
+	ErrInvalidPatch            = errors.New("invalid patch")
+	ErrPatchConflict           = errors.New("patch can't be applied")
+	ErrDocumentChanged         = errors.New("document was changed")
+	ErrRangeNotSatisfiable     = errors.New("range not satisfiable")
+	ErrUploadNotFound          = errors.New("upload not found")
+	ErrInvalidUploadSize       = errors.New("invalid upload size")
+	ErrInvalidUploadOffset     = errors.New("invalid upload offset")
+	ErrUploadOffsetMismatch    = errors.New("upload offset mismatch")
+	ErrChunkIsTooLarge         = errors.New("chunk is too large")
+	ErrUploadIsIncomplete      = errors.New("upload is incomplete")
+	ErrUploadIsCompleting      = errors.New("upload is being completed")
+	ErrMimeNotAllowed          = errors.New("file type is not allowed")
+	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
+	ErrRefreshTokenReused      = errors.New("refresh token reused")
+	ErrSessionNotFound         = errors.New("session not found")
 )
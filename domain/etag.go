@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// blobKeyPrefix - files are stored under keys derived from their SHA-256 digest
+const blobKeyPrefix = "sha256/"
+
+// ETag - strong entity tag of the document: the SHA-256 digest of the file for files
+// and of the JSON representation for JSON documents
+func (d *Document) ETag() string {
+	if d.IsFile {
+		if strings.HasPrefix(d.FilePath, blobKeyPrefix) {
+			return `"` + path.Base(d.FilePath) + `"`
+		}
+
+		// Files uploaded before the content addressing are never rewritten in place
+		digest := sha256.Sum256([]byte(d.FilePath))
+		return `"` + hex.EncodeToString(digest[:]) + `"`
+	}
+
+	b, _ := json.Marshal(d)
+	digest := sha256.Sum256(b)
+
+	return `"` + hex.EncodeToString(digest[:]) + `"`
+}
+
+// MatchETag - checks the entity tag against the If-Match header: "*" or a comma-separated list of tags.
+// Weak tags never match, If-Match requires the strong comparison
+func MatchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchETagWeak - checks the entity tag against the If-None-Match header, weak tags match too
+func MatchETagWeak(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFilters - limit of filters in one listing request
+const maxFilters = 10
+
+// JSONFieldPrefix - marks filters on fields inside the JSON data of the document: "json.author.name"
+const JSONFieldPrefix = "json."
+
+type FilterOperator string
+
+const (
+	OperatorEq   FilterOperator = "eq"
+	OperatorNe   FilterOperator = "ne"
+	OperatorLike FilterOperator = "like"
+	OperatorGt   FilterOperator = "gt"
+	OperatorLt   FilterOperator = "lt"
+	OperatorIn   FilterOperator = "in"
+)
+
+type FieldKind int
+
+const (
+	FieldString FieldKind = iota
+	FieldBool
+	FieldInt
+	FieldTime
+	FieldJSON
+)
+
+// DocumentFields - document fields that listings can be filtered and sorted by
+var DocumentFields = map[string]FieldKind{
+	"name":          FieldString,
+	"mime":          FieldString,
+	"mime_mismatch": FieldBool,
+	"is_file":       FieldBool,
+	"is_public":     FieldBool,
+	"version":       FieldInt,
+	"created_at":    FieldTime,
+	"updated_at":    FieldTime,
+}
+
+// operators - operators applicable to the field kind
+var operators = map[FieldKind][]FilterOperator{
+	FieldString: {OperatorEq, OperatorNe, OperatorLike, OperatorGt, OperatorLt, OperatorIn},
+	FieldBool:   {OperatorEq, OperatorNe},
+	FieldInt:    {OperatorEq, OperatorNe, OperatorGt, OperatorLt, OperatorIn},
+	FieldTime:   {OperatorEq, OperatorNe, OperatorGt, OperatorLt},
+	FieldJSON:   {OperatorEq, OperatorNe, OperatorLike, OperatorGt, OperatorLt, OperatorIn},
+}
+
+// Filter - condition on a document field, all filters of a listing are combined with AND
+type Filter struct {
+	Field    string
+	Kind     FieldKind
+	Path     []string // keys inside the JSON data, only for FieldJSON
+	Operator FilterOperator
+	Values   []string // several values only for the in operator
+}
+
+// ParseFilter - parses a filter in "field:operator:value" form. The field is one of DocumentFields
+// or "json.key.key" for the JSON data, values of the in operator are separated by commas
+func ParseFilter(filter string) (Filter, error) {
+	field, rest, _ := strings.Cut(filter, ":")
+	operator, value, found := strings.Cut(rest, ":")
+	if !found {
+		return Filter{}, fmt.Errorf("%w: %v", ErrInvalidFilter, filter)
+	}
+
+	f := Filter{
+		Field:    field,
+		Operator: FilterOperator(operator),
+		Values:   []string{value},
+	}
+
+	if path, ok := strings.CutPrefix(field, JSONFieldPrefix); ok {
+		f.Kind = FieldJSON
+		f.Path = strings.Split(path, ".")
+		for _, key := range f.Path {
+			if key == "" {
+				return Filter{}, fmt.Errorf("%w: invalid JSON path: %v", ErrInvalidFilter, field)
+			}
+		}
+	} else if kind, ok := DocumentFields[field]; ok {
+		f.Kind = kind
+	} else {
+		return Filter{}, fmt.Errorf("%w: unknown field: %v", ErrInvalidFilter, field)
+	}
+
+	if !f.Kind.supports(f.Operator) {
+		return Filter{}, fmt.Errorf("%w: operator %v isn't supported by %v", ErrInvalidFilter, operator, field)
+	}
+
+	if f.Operator == OperatorIn {
+		f.Values = strings.Split(value, ",")
+	}
+
+	for _, v := range f.Values {
+		if !f.Kind.Valid(v) {
+			return Filter{}, fmt.Errorf("%w: invalid value of %v: %v", ErrInvalidFilter, field, v)
+		}
+	}
+
+	return f, nil
+}
+
+func (k FieldKind) supports(operator FilterOperator) bool {
+	for _, op := range operators[k] {
+		if op == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Valid - checks that the value can be compared with the field of the kind
+func (k FieldKind) Valid(value string) bool {
+	switch k {
+	case FieldBool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case FieldInt:
+		_, err := strconv.Atoi(value)
+		return err == nil
+	case FieldTime:
+		_, err := ParseTime(value)
+		return err == nil
+	}
+
+	return true
+}
+
+// ParseTime - parses a time in RFC 3339 form or a date "2006-01-02"
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+// Sort - ordering of a listing by a document field
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort - parses a comma-separated list of DocumentFields, "-field" sorts in descending order
+func ParseSort(sort string) ([]Sort, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(sort, ",")
+	sorts := make([]Sort, 0, len(fields))
+	for _, field := range fields {
+		field, desc := strings.CutPrefix(field, "-")
+		if _, ok := DocumentFields[field]; !ok {
+			return nil, fmt.Errorf("%w: unknown field: %v", ErrInvalidSort, field)
+		}
+
+		sorts = append(sorts, Sort{Field: field, Desc: desc})
+	}
+
+	return sorts, nil
+}
+
+// SetQuery - sets filters and the sort of the listing from query parameters
+func (p *FilterParams) SetQuery(filters []string, sort string) error {
+	if len(filters) > maxFilters {
+		return fmt.Errorf("%w: too many filters, max %v", ErrInvalidFilter, maxFilters)
+	}
+
+	for _, filter := range filters {
+		f, err := ParseFilter(filter)
+		if err != nil {
+			return err
+		}
+
+		p.Filters = append(p.Filters, f)
+	}
+
+	sorts, err := ParseSort(sort)
+	if err != nil {
+		return err
+	}
+
+	p.Sort = sorts
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   Filter
+		err    error
+	}{
+		{
+			name:   "string eq",
+			filter: "name:eq:report",
+			want:   Filter{Field: "name", Kind: FieldString, Operator: OperatorEq, Values: []string{"report"}},
+		},
+		{
+			name:   "value with colons",
+			filter: "name:eq:a:b",
+			want:   Filter{Field: "name", Kind: FieldString, Operator: OperatorEq, Values: []string{"a:b"}},
+		},
+		{
+			name:   "empty value",
+			filter: "mime:ne:",
+			want:   Filter{Field: "mime", Kind: FieldString, Operator: OperatorNe, Values: []string{""}},
+		},
+		{
+			name:   "in splits values",
+			filter: "version:in:1,2,3",
+			want:   Filter{Field: "version", Kind: FieldInt, Operator: OperatorIn, Values: []string{"1", "2", "3"}},
+		},
+		{
+			name:   "eq doesn't split values",
+			filter: "name:eq:a,b",
+			want:   Filter{Field: "name", Kind: FieldString, Operator: OperatorEq, Values: []string{"a,b"}},
+		},
+		{
+			name:   "bool",
+			filter: "is_public:eq:true",
+			want:   Filter{Field: "is_public", Kind: FieldBool, Operator: OperatorEq, Values: []string{"true"}},
+		},
+		{
+			name:   "time as date",
+			filter: "created_at:gt:2024-01-31",
+			want:   Filter{Field: "created_at", Kind: FieldTime, Operator: OperatorGt, Values: []string{"2024-01-31"}},
+		},
+		{
+			name:   "time as RFC 3339",
+			filter: "updated_at:lt:2024-01-31T10:00:00Z",
+			want:   Filter{Field: "updated_at", Kind: FieldTime, Operator: OperatorLt, Values: []string{"2024-01-31T10:00:00Z"}},
+		},
+		{
+			name:   "JSON path",
+			filter: "json.author.name:like:Iv%",
+			want: Filter{Field: "json.author.name", Kind: FieldJSON, Path: []string{"author", "name"},
+				Operator: OperatorLike, Values: []string{"Iv%"}},
+		},
+		{
+			name:   "JSON in",
+			filter: "json.status:in:new,\"done\"",
+			want: Filter{Field: "json.status", Kind: FieldJSON, Path: []string{"status"},
+				Operator: OperatorIn, Values: []string{"new", "\"done\""}},
+		},
+		{name: "no operator", filter: "name", err: ErrInvalidFilter},
+		{name: "no value", filter: "name:eq", err: ErrInvalidFilter},
+		{name: "unknown field", filter: "owner:eq:me", err: ErrInvalidFilter},
+		{name: "hidden column", filter: "file_path:eq:x", err: ErrInvalidFilter},
+		{name: "unknown operator", filter: "name:regex:x", err: ErrInvalidFilter},
+		{name: "like on int", filter: "version:like:1", err: ErrInvalidFilter},
+		{name: "gt on bool", filter: "is_file:gt:true", err: ErrInvalidFilter},
+		{name: "in on bool", filter: "is_file:in:true,false", err: ErrInvalidFilter},
+		{name: "like on time", filter: "created_at:like:2024", err: ErrInvalidFilter},
+		{name: "in on time", filter: "created_at:in:2024-01-01", err: ErrInvalidFilter},
+		{name: "invalid bool", filter: "is_public:eq:yes", err: ErrInvalidFilter},
+		{name: "invalid int", filter: "version:gt:1.5", err: ErrInvalidFilter},
+		{name: "invalid int in the list", filter: "version:in:1,x", err: ErrInvalidFilter},
+		{name: "invalid time", filter: "created_at:gt:yesterday", err: ErrInvalidFilter},
+		{name: "empty JSON path", filter: "json.:eq:1", err: ErrInvalidFilter},
+		{name: "empty JSON key in the middle", filter: "json.a..b:eq:1", err: ErrInvalidFilter},
+		{name: "empty JSON key at the end", filter: "json.a.:eq:1", err: ErrInvalidFilter},
+		{name: "JSON without path", filter: "json:eq:1", err: ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseFilter(%q) error = %v, want %v", tt.filter, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort string
+		want []Sort
+		err  error
+	}{
+		{name: "empty", sort: "", want: nil},
+		{name: "one field", sort: "name", want: []Sort{{Field: "name"}}},
+		{name: "descending", sort: "-created_at", want: []Sort{{Field: "created_at", Desc: true}}},
+		{
+			name: "several fields",
+			sort: "-is_file,name,-version",
+			want: []Sort{{Field: "is_file", Desc: true}, {Field: "name"}, {Field: "version", Desc: true}},
+		},
+		{name: "unknown field", sort: "owner", err: ErrInvalidSort},
+		{name: "JSON field", sort: "json.a", err: ErrInvalidSort},
+		{name: "empty field", sort: "name,", err: ErrInvalidSort},
+		{name: "double minus", sort: "--name", err: ErrInvalidSort},
+		{name: "spaces", sort: "name, version", err: ErrInvalidSort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.sort)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseSort(%q) error = %v, want %v", tt.sort, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSort(%q) = %+v, want %+v", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []string
+		sort    string
+		err     error
+	}{
+		{name: "no filters", filters: nil, sort: ""},
+		{name: "filters and sort", filters: []string{"name:like:a%", "is_file:eq:false"}, sort: "-name"},
+		{name: "invalid filter", filters: []string{"name:like:a%", "version:eq:x"}, err: ErrInvalidFilter},
+		{name: "invalid sort", filters: []string{"name:like:a%"}, sort: "size", err: ErrInvalidSort},
+		{
+			name:    "too many filters",
+			filters: []string{"name:eq:1", "name:eq:2", "name:eq:3", "name:eq:4", "name:eq:5", "name:eq:6", "name:eq:7", "name:eq:8", "name:eq:9", "name:eq:10", "name:eq:11"},
+			err:     ErrInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params FilterParams
+			if err := params.SetQuery(tt.filters, tt.sort); !errors.Is(err, tt.err) {
+				t.Fatalf("SetQuery() error = %v, want %v", err, tt.err)
+			}
+
+			if tt.err == nil && len(params.Filters) != len(tt.filters) {
+				t.Errorf("SetQuery() filters = %v, want %v", len(params.Filters), len(tt.filters))
+			}
+		})
+	}
+}
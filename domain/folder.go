@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Folder - node of the user's document tree, grants on a folder are inherited
+// by documents in it and in its subfolders
+type Folder struct {
+	Id        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	ParentId  *string   `json:"parent_id" db:"parent_id"` // nil - root folder
+	CreatedAt time.Time `json:"created" db:"created_at"`
+	UpdatedAt time.Time `json:"updated" db:"updated_at"`
+}
+
+// FolderUpdate - folder changes, nil fields are left unchanged. Empty ParentId moves the folder to the root
+type FolderUpdate struct {
+	Name     *string
+	ParentId *string
+}
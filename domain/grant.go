@@ -0,0 +1,80 @@
+package domain
+
+import "strings"
+
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+	PermissionShare Permission = "share"
+	PermissionOwner Permission = "owner"
+)
+
+// Rank - permissions are ordered, every permission includes the lower ones
+func (p Permission) Rank() int {
+	switch p {
+	case PermissionRead:
+		return 1
+	case PermissionWrite:
+		return 2
+	case PermissionShare:
+		return 3
+	case PermissionOwner:
+		return 4
+	}
+
+	return 0
+}
+
+// Grantable - permission can be granted to other users
+func (p Permission) Grantable() bool {
+	return p == PermissionRead || p == PermissionWrite || p == PermissionShare
+}
+
+// GroupPrefix - marks a group name in grant subjects: "@team"
+const GroupPrefix = "@"
+
+// Grant - permission of a user or a group on the document, only one of Login and Group is set
+type Grant struct {
+	Login      string     `json:"login,omitempty" db:"login"`
+	Group      string     `json:"group,omitempty" db:"group_name"`
+	Permission Permission `json:"permission" db:"permission"`
+}
+
+// ParseGrant - parses a grant in "subject" or "subject:permission" form, where the subject
+// is a login or "@group". The default permission is read
+func ParseGrant(grant string) (Grant, error) {
+	subject, permission, found := strings.Cut(grant, ":")
+	if !found {
+		permission = string(PermissionRead)
+	}
+
+	if !Permission(permission).Grantable() {
+		return Grant{}, ErrInvalidPermission
+	}
+
+	return NewGrant(subject, Permission(permission)), nil
+}
+
+// NewGrant - creates a grant for the subject, a login or "@group"
+func NewGrant(subject string, permission Permission) Grant {
+	if group, ok := strings.CutPrefix(subject, GroupPrefix); ok {
+		return Grant{Group: group, Permission: permission}
+	}
+
+	return Grant{Login: subject, Permission: permission}
+}
+
+// Subject - login of the user or "@group"
+func (g Grant) Subject() string {
+	if g.Group != "" {
+		return GroupPrefix + g.Group
+	}
+
+	return g.Login
+}
+
+func (g Grant) String() string {
+	return g.Subject() + ":" + string(g.Permission)
+}
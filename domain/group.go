@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Group - named set of users that can receive document grants
+type Group struct {
+	Id        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Owner     string    `json:"owner" db:"owner"` // owner login
+	Members   []string  `json:"members,omitempty"`
+	CreatedAt time.Time `json:"created" db:"created_at"`
+}
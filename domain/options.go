@@ -8,10 +8,12 @@ const (
 )
 
 type FilterParams struct {
-	Key    string
-	Value  string
-	Limit  int
-	Offset int
+	Filters []Filter
+	Sort    []Sort // empty - the default order of the listing
+	Cursor  Cursor // empty id - the listing starts at Offset
+	Total   bool   // count all documents matching the filters
+	Limit   int
+	Offset  int
 }
 
 func PrepareFillterParams(key, value string, limit, page string) *FilterParams {
@@ -25,10 +27,20 @@ func PrepareFillterParams(key, value string, limit, page string) *FilterParams {
 		pag = defaultPage
 	}
 
-	return &FilterParams{
-		Key:    key,
-		Value:  value,
+	params := &FilterParams{
 		Limit:  lim,
 		Offset: (pag - 1) * lim,
 	}
+
+	// The legacy key/value pair is a like filter on a text field
+	if kind, ok := DocumentFields[key]; ok && kind == FieldString && value != "" {
+		params.Filters = append(params.Filters, Filter{
+			Field:    key,
+			Kind:     kind,
+			Operator: OperatorLike,
+			Values:   []string{value},
+		})
+	}
+
+	return params
 }
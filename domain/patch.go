@@ -0,0 +1,20 @@
+package domain
+
+type PatchType string
+
+const (
+	PatchJSON  PatchType = "application/json-patch+json"  // RFC 6902
+	PatchMerge PatchType = "application/merge-patch+json" // RFC 7396
+)
+
+// DataPatch - change of the JSON data of the document
+type DataPatch struct {
+	Type    PatchType
+	Patch   []byte
+	IfMatch string // If-Match header, empty - the patch is applied to the latest data
+}
+
+// Valid - checks that the patch type is supported
+func (t PatchType) Valid() bool {
+	return t == PatchJSON || t == PatchMerge
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Schema - JSON Schema of documents of one type, documents refer to the schema by its name
+type Schema struct {
+	Id        string          `json:"id" db:"id"`
+	Name      string          `json:"name" db:"name"`
+	Owner     string          `json:"owner" db:"owner"` // owner login
+	Schema    json.RawMessage `json:"schema,omitempty" db:"schema" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated" db:"updated_at"`
+}
+
+// FieldError - violation of the schema by a value of the JSON
+type FieldError struct {
+	Field   string `json:"field"` // JSON pointer to the value, empty - the whole JSON
+	Message string `json:"message"`
+}
+
+// ValidationError - JSON doesn't match the schema, Err is ErrDocumentDataIsInvalid or ErrInvalidSchema
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// SearchResult - document found by full-text search. Highlighted fragments are HTML:
+// matched words are marked with <b></b>, the rest of the text is escaped
+type SearchResult struct {
+	Id            string    `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name"`
+	Mime          string    `json:"mime" db:"mime"`
+	IsFile        bool      `json:"is_file" db:"is_file"`
+	IsPublic      bool      `json:"is_public" db:"is_public"`
+	FolderId      *string   `json:"folder_id" db:"folder_id"`
+	UpdatedAt     time.Time `json:"updated" db:"updated_at"`
+	Rank          float64   `json:"rank" db:"rank"`
+	NameHighlight string    `json:"name_highlight" db:"name_highlight"`
+	Snippet       string    `json:"snippet,omitempty" db:"snippet"`
+}
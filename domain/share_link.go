@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// ShareLink - token that gives access to the document without authorization
+type ShareLink struct {
+	Id           string    `json:"id" db:"id"`
+	Token        string    `json:"token,omitempty" db:"-"` // returned only when the link is created
+	TokenHash    string    `json:"-" db:"token_hash"`
+	MaxDownloads *int      `json:"max_downloads" db:"max_downloads"` // nil - unlimited
+	Downloads    int       `json:"downloads" db:"downloads"`
+	CreatedAt    time.Time `json:"created" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires" db:"expires_at"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Upload - resumable upload of a large file, the file is sent in chunks
+// and becomes a document when the upload is completed
+type Upload struct {
+	Id         string    `json:"id" db:"id"`
+	FileName   string    `json:"filename" db:"file_name"`
+	Size       int64     `json:"size" db:"size"`
+	Offset     int64     `json:"offset" db:"received"` // bytes received, the next chunk starts here
+	Completing bool      `json:"-" db:"completing"`    // the document is being created, the lease ends with expires
+	CreatedAt  time.Time `json:"created" db:"created_at"`
+	ExpiresAt  time.Time `json:"expires" db:"expires_at"` // abandoned uploads are deleted after it
+}
+
+// UploadChunk - part of the upload kept in the storage until the upload is completed
+type UploadChunk struct {
+	Start   int64  `db:"start"`
+	Size    int64  `db:"size"`
+	BlobKey string `db:"blob_key"`
+}
@@ -3,11 +3,14 @@ package app
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,10 +22,12 @@ import (
 	"github.com/sixojke/test-astral/internal/server"
 	"github.com/sixojke/test-astral/internal/service"
 	"github.com/sixojke/test-astral/pkg/auth"
+	"github.com/sixojke/test-astral/pkg/cache"
 	"github.com/sixojke/test-astral/pkg/db"
 	"github.com/sixojke/test-astral/pkg/hash"
 	"github.com/sixojke/test-astral/pkg/logger"
 	"github.com/sixojke/test-astral/pkg/migrations"
+	"github.com/sixojke/test-astral/pkg/storage"
 )
 
 const (
@@ -30,6 +35,9 @@ const (
 	env     = ".env"
 )
 
+var convertLegacyPaths = flag.Bool("convert-legacy-paths", false,
+	"convert file paths saved before the blob storage into keys relative to uploads_dir and exit")
+
 // @title All social networks shop API
 // @version 1.0
 // @description REST API for shop
@@ -41,6 +49,8 @@ const (
 // @in header
 // @name Authorization
 func Run() {
+	flag.Parse()
+
 	// Get project directories
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -57,7 +67,11 @@ func Run() {
 	enableLogger(cfg.Logger.LogLevel)
 
 	// Init hasher
-	hasher := hash.NewSHA1Hasher(cfg.Hasher.Salt)
+	hasher := hash.NewArgon2Hasher(hash.Argon2Params{
+		Memory:      cfg.Hasher.Argon2.MemoryKb,
+		Iterations:  cfg.Hasher.Argon2.Iterations,
+		Parallelism: cfg.Hasher.Argon2.Parallelism,
+	}, hash.NewSHA1Hasher(cfg.Hasher.Salt))
 
 	// Init token manager
 	tokenManager, err := auth.NewManager(cfg.Authorization.JWT.SigningKey)
@@ -85,17 +99,59 @@ func Run() {
 	}
 	logger.Info("[POSTGRES] Migrate successful")
 
+	// Init blob storage
+	blobStore, err := newBlobStore(cfg.Documents)
+	if err != nil {
+		logger.Fatalf("error init blob storage: %v", err)
+	}
+	logger.Infof("[STORAGE] Backend %v initialized", cfg.Documents.Storage.Backend)
+
+	// Init document cache
+	var documentCache *cache.Cache
+	if cfg.Documents.Cache.TTL > 0 {
+		documentCache = cache.New(cfg.Documents.Cache.TTL, cfg.Documents.Cache.MaxEntries)
+	}
+
+	if documentCache != nil && cfg.Documents.Cache.StatsInterval > 0 {
+		go reportCacheStats(documentCache, cfg.Documents.Cache.StatsInterval)
+	}
+
 	repo := repository.NewService(&repository.Deps{
-		Postgres: postgres,
+		Postgres:      postgres,
+		DocumentCache: documentCache,
 	})
 
+	// File paths saved before the blob storage start with the uploads directory.
+	// They are converted once on demand, migration 000006 handles the default directory
+	if *convertLegacyPaths {
+		prefix := strings.TrimSuffix(cfg.Documents.UploadsDir, "/") + "/"
+		converted, err := repo.Blob.StripKeyPrefix(prefix)
+		if err != nil {
+			logger.Fatalf("error convert legacy file paths: %v", err)
+		}
+
+		logger.Infof("[STORAGE] Converted legacy file paths of %v documents", converted)
+
+		return
+	}
+
 	service := service.NewService(&service.Deps{
 		Repository:   repo,
 		Config:       cfg,
 		Hasher:       hasher,
 		TokenManager: tokenManager,
+		BlobStore:    blobStore,
 	})
 
+	service.Extractor.Start()
+	logger.Infof("[EXTRACTOR] Started with %v workers", cfg.Documents.Extractor.Workers)
+
+	service.UploadCleaner.Start()
+	logger.Infof("[UPLOADS] Cleanup every %v", cfg.Documents.Uploads.CleanupInterval)
+
+	service.Janitor.Start()
+	logger.Infof("[JANITOR] Cleanup every %v, orphaned files: %v", cfg.Janitor.Interval, cfg.Janitor.Orphans.Action)
+
 	handler := delivery.NewHandler(service, cfg, tokenManager)
 
 	srv := server.NewServer(cfg.HTTPServer, handler.Init())
@@ -106,14 +162,52 @@ func Run() {
 	}()
 	logger.Infof("[SERVER] Started on port :%v", cfg.HTTPServer.Port)
 
-	shutdown(srv, postgres)
+	shutdown(srv, postgres, service.Extractor, service.UploadCleaner, service.Janitor)
+
+	if documentCache != nil {
+		logCacheStats(documentCache)
+	}
+}
+
+// reportCacheStats - logs the cache statistics periodically until the process exits
+func reportCacheStats(c *cache.Cache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		logCacheStats(c)
+	}
+}
+
+func logCacheStats(c *cache.Cache) {
+	stats := c.Stats()
+	logger.Infof("[CACHE] hits=%v misses=%v entries=%v", stats.Hits, stats.Misses, stats.Entries)
+}
+
+func newBlobStore(cfg config.Documents) (storage.BlobStore, error) {
+	switch cfg.Storage.Backend {
+	case "", "local":
+		return storage.NewLocalStore(cfg.UploadsDir)
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			UseSSL:    cfg.Storage.S3.UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %v", cfg.Storage.Backend)
+	}
 }
 
 func enableLogger(logLevel int) {
 	logger.NewLogger(zerolog.Level(logLevel), os.Stdout)
 }
 
-func shutdown(srv *server.Server, postgres *sqlx.DB) {
+func shutdown(srv *server.Server, postgres *sqlx.DB, extractor *service.ContentExtractor, uploadCleaner *service.UploadCleaner,
+	janitor *service.Janitor) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
@@ -128,5 +222,9 @@ func shutdown(srv *server.Server, postgres *sqlx.DB) {
 		logger.Errorf("failed to stop server: %v", err)
 	}
 
+	extractor.Stop()
+	uploadCleaner.Stop()
+	janitor.Stop()
+
 	postgres.Close()
 }
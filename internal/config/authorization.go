@@ -5,9 +5,14 @@ import "time"
 type Authorization struct {
 	AdminToken string
 	JWT        JWT
+	// RevocationCheck - access tokens are looked up in the database on every request. Without it only
+	// the tokens revoked by this instance are rejected before they expire
+	RevocationCheck bool `mapstructure:"revocation_check"`
 }
 
 type JWT struct {
 	SigningKey     string
 	AccessTokenTTL time.Duration `mapstructure:"access_token_ttl"`
+	// RefreshTokenTTL - the session expires if it isn't refreshed for this time
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
 }
@@ -18,6 +18,7 @@ type Config struct {
 	Authorization Authorization
 	Hasher        Hasher
 	Documents     Documents
+	Janitor       Janitor
 }
 
 // Init - a function for initializing the application configuration
@@ -35,7 +36,9 @@ func Init(configPath string, envFile string) (*Config, error) {
 		{fileName: "http_server.yaml", key: "http_server", rawVal: &config.HTTPServer},
 		{fileName: "postgres.yaml", key: "postgres", rawVal: &config.Postgres},
 		{fileName: "auth.yaml", key: "auth", rawVal: &config.Authorization},
+		{fileName: "hasher.yaml", key: "hasher", rawVal: &config.Hasher},
 		{fileName: "documents.yaml", key: "documents", rawVal: &config.Documents},
+		{fileName: "janitor.yaml", key: "janitor", rawVal: &config.Janitor},
 	}
 
 	// Reading configuration from YAML files
@@ -88,5 +91,8 @@ func configFromEnv(cfg *Config, envFile string) error {
 
 	cfg.Hasher.Salt = os.Getenv("HASHER_SALT")
 
+	cfg.Documents.Storage.S3.AccessKey = os.Getenv("S3_ACCESS_KEY")
+	cfg.Documents.Storage.S3.SecretKey = os.Getenv("S3_SECRET_KEY")
+
 	return nil
 }
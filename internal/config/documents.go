@@ -1,5 +1,66 @@
 package config
 
+import "time"
+
 type Documents struct {
-	UploadsDir string `mapstructure:"uploads_dir"`
+	UploadsDir string           `mapstructure:"uploads_dir"`
+	Storage    DocumentsStorage `mapstructure:"storage"`
+	Cache      DocumentsCache   `mapstructure:"cache"`
+	ShareLinks DocumentsLinks   `mapstructure:"share_links"`
+	Extractor  DocumentsExtract `mapstructure:"extractor"`
+	Uploads    DocumentsUploads `mapstructure:"uploads"`
+	Mime       DocumentsMime    `mapstructure:"mime"`
+}
+
+type DocumentsStorage struct {
+	// Backend - "local" stores files in UploadsDir, "s3" in an S3-compatible bucket
+	Backend string    `mapstructure:"backend"`
+	S3      StorageS3 `mapstructure:"s3"`
+}
+
+type StorageS3 struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+	AccessKey string
+	SecretKey string
+}
+
+type DocumentsCache struct {
+	// TTL - lifetime of a cache entry, zero disables the cache
+	TTL        time.Duration `mapstructure:"ttl"`
+	MaxEntries int           `mapstructure:"max_entries"`
+	// StatsInterval - period of logging hits and misses, zero disables it
+	StatsInterval time.Duration `mapstructure:"stats_interval"`
+}
+
+type DocumentsLinks struct {
+	// DefaultTTL - lifetime of a share link if the expiration isn't passed
+	DefaultTTL time.Duration `mapstructure:"default_ttl"`
+	MaxTTL     time.Duration `mapstructure:"max_ttl"`
+}
+
+type DocumentsExtract struct {
+	// Workers - number of files processed at once, zero disables the extraction
+	Workers       int   `mapstructure:"workers"`
+	QueueSize     int   `mapstructure:"queue_size"`
+	MaxFileSizeMb int64 `mapstructure:"max_file_size_mb"`
+	// ScanInterval - how often files missed by the queue are looked for
+	ScanInterval time.Duration `mapstructure:"scan_interval"`
+}
+
+type DocumentsUploads struct {
+	MaxFileSizeMb  int64 `mapstructure:"max_file_size_mb"`
+	MaxChunkSizeMb int64 `mapstructure:"max_chunk_size_mb"`
+	// TTL - abandoned uploads are deleted after TTL without new chunks
+	TTL             time.Duration `mapstructure:"ttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+type DocumentsMime struct {
+	// Allow - types of files that can be uploaded, empty allows any type. "type/*" matches the whole type
+	Allow []string `mapstructure:"allow"`
+	// Deny - types of files that can't be uploaded, a denied type denies its subtypes too
+	Deny []string `mapstructure:"deny"`
 }
@@ -1,5 +1,13 @@
 package config
 
 type Hasher struct {
-	Salt string
+	// Salt - global salt of the legacy SHA-1 hashes, they are replaced with argon2id on the next sign in
+	Salt   string
+	Argon2 HasherArgon2 `mapstructure:"argon2"`
+}
+
+type HasherArgon2 struct {
+	MemoryKb    uint32 `mapstructure:"memory_kb"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
 }
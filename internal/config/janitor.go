@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type Janitor struct {
+	// Interval - how often expired sessions are purged and the storage is reconciled, zero disables the janitor
+	Interval time.Duration  `mapstructure:"interval"`
+	Orphans  JanitorOrphans `mapstructure:"orphans"`
+}
+
+type JanitorOrphans struct {
+	// Action - "report" only logs files no document references, "delete" deletes them
+	Action string `mapstructure:"action"`
+	// MinAge - younger files are skipped, they may be stored by a request in progress
+	MinAge time.Duration `mapstructure:"min_age"`
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sixojke/test-astral/domain"
@@ -27,12 +28,30 @@ func (a *authUserInp) validate() error {
 }
 
 type authUserResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+func newAuthUserResponse(tokens *domain.Tokens) authUserResponse {
+	return authUserResponse{
+		Token:        tokens.AccessToken,
+		ExpiresAt:    tokens.AccessExpiresAt,
+		RefreshToken: tokens.RefreshToken,
+	}
+}
+
+type refreshTokensInp struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type getSessionsData struct {
+	Sessions []domain.Session `json:"sessions"`
 }
 
 // @Summary Auth user
 // @Tags auth
-// @Description User login
+// @Description User login, returns the access token and the refresh token to renew it with POST /auth/refresh
 // @ModuleID authUser
 // @Accept json
 // @Produce json
@@ -55,7 +74,7 @@ func (h *Handler) authUser(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.SignIn(inp.Login, inp.Password)
+	tokens, err := h.service.SignIn(inp.Login, inp.Password, getClientByContext(c))
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
@@ -66,20 +85,60 @@ func (h *Handler) authUser(c *gin.Context) {
 		return
 	}
 
-	newResponse(c, http.StatusOK, nil, authUserResponse{
-		Token: token,
-	})
+	newResponse(c, http.StatusOK, nil, newAuthUserResponse(tokens))
+}
+
+// @Summary Refresh tokens
+// @Tags auth
+// @Description Issue new access and refresh tokens of the session. The refresh token can be used once, a used one presented again revokes the session
+// @ModuleID refreshTokens
+// @Accept json
+// @Produce json
+// @Param input body refreshTokensInp true "Refresh token"
+// @Success 200 {object} swagResponse{response=authUserResponse} "New tokens"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 401 {object} swagError "Refresh token is invalid, expired or reused"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /auth/refresh [post]
+func (h *Handler) refreshTokens(c *gin.Context) {
+	var inp refreshTokensInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if inp.RefreshToken == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrInvalidRefreshToken.Error(), domain.ErrInvalidRefreshToken.Error())
+
+		return
+	}
+
+	tokens, err := h.service.User.Refresh(inp.RefreshToken, getClientByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			errResponse(c, http.StatusUnauthorized, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, newAuthUserResponse(tokens))
 }
 
 // @Summary Delete session by token
+// @Security UsersAuth
 // @Tags auth
-// @Description Delete session by token
+// @Description Delete the session of the access token with all its tokens, only own tokens can be deleted
 // @ModuleID deleteSession
 // @Accept json
 // @Produce json
 // @Param token path string true "Session token"
 // @Success 200 {object} swagResponse{response=map[string]bool} "Success"
 // @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Session not found"
 // @Failure 500 {object} swagError "Internal Server Error"
 // @Router /auth/{token} [delete]
 func (h *Handler) deleteSession(c *gin.Context) {
@@ -91,8 +150,12 @@ func (h *Handler) deleteSession(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.User.DeleteSession(token); err != nil {
-		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+	if err := h.service.User.DeleteSession(token, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
 
 		return
 	}
@@ -101,3 +164,85 @@ func (h *Handler) deleteSession(c *gin.Context) {
 		token: true,
 	})
 }
+
+// @Summary Get sessions
+// @Security UsersAuth
+// @Tags auth
+// @Description Get active sessions of the user with the client they were last used from, the session of the request is marked as current
+// @ModuleID getSessions
+// @Accept json
+// @Produce json
+// @Success 200 {object} swagData{data=getSessionsData} "Sessions list"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /auth/sessions [get]
+func (h *Handler) getSessions(c *gin.Context) {
+	sessions, err := h.service.User.GetSessions(getUserIdByContext(c), getSessionIdByContext(c))
+	if err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getSessionsData{
+		Sessions: sessions,
+	}, nil)
+}
+
+// @Summary Delete session by ID
+// @Security UsersAuth
+// @Tags auth
+// @Description Revoke the session with all its tokens, only own sessions can be revoked
+// @ModuleID deleteSessionById
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Session not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) deleteSessionById(c *gin.Context) {
+	sessionId := c.Param("id")
+
+	if sessionId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.User.DeleteUserSession(sessionId, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		sessionId: true,
+	})
+}
+
+// @Summary Delete all sessions
+// @Security UsersAuth
+// @Tags auth
+// @Description Log out everywhere: revoke all sessions of the user with all their tokens, including the current one
+// @ModuleID deleteSessions
+// @Accept json
+// @Produce json
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /auth/sessions [delete]
+func (h *Handler) deleteSessions(c *gin.Context) {
+	if err := h.service.User.DeleteUserSessions(getUserIdByContext(c)); err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		"sessions": true,
+	})
+}
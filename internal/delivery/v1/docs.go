@@ -1,9 +1,12 @@
 package v1
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sixojke/test-astral/domain"
@@ -17,6 +20,8 @@ type uploadDocumentInpMeta struct {
 	Mime         string   `form:"mime"`
 	Grants       []string `form:"grant[]"`
 	DocumentData string   `form:"json"`
+	Type         string   `form:"type"`
+	FolderId     string   `form:"folder"`
 }
 
 func (u *uploadDocumentInpMeta) validate() error {
@@ -24,12 +29,18 @@ func (u *uploadDocumentInpMeta) validate() error {
 		return domain.ErrNameIsEmpty
 	}
 
+	if u.DocumentData != "" && !json.Valid([]byte(u.DocumentData)) {
+		return domain.ErrInvalidJSON
+	}
+
 	return nil
 }
 
 type uploadDocumentData struct {
-	DocumentData interface{} `json:"json"`
-	File         string      `json:"file"`
+	Id            string      `json:"id"`
+	DocumentData  interface{} `json:"json"`
+	File          string      `json:"file"`
+	UnknownGrants []string    `json:"unknown_grants,omitempty"`
 }
 
 // @Summary Upload document
@@ -42,12 +53,16 @@ type uploadDocumentData struct {
 // @Param name formData string false "Document name"
 // @Param is_file formData bool false "Is file"
 // @Param public formData bool false "Is public"
-// @Param mime formData string false "Document mime type"
-// @Param grant[] formData string false "Grant array"
-// @Param json formData string false "Document data"
+// @Param mime formData string false "Document mime type. The type of the file is detected by its content, if the passed one contradicts it the detected one is stored and mime_mismatch is set"
+// @Param grant[] formData string false "Grant array, login or @group with an optional :permission (read, write, share)"
+// @Param json formData string false "Document data, JSON"
+// @Param type formData string false "Schema name, the data is validated against the schema"
+// @Param folder formData string false "Folder ID, own folders only"
 // @Param file formData file false "Document file"
 // @Success 200 {object} swagData{data=uploadDocumentData} "Document uploaded successfully"
-// @Failure 400 {object} swagError "Bad Request"
+// @Failure 400 {object} swagError "Bad Request, details list fields that don't match the schema"
+// @Failure 404 {object} swagError "Folder or schema not found"
+// @Failure 415 {object} swagError "File type is not allowed"
 // @Failure 500 {object} swagError "Internal Server Error"
 // @Router /docs [post]
 func (h *Handler) uploadDocument(c *gin.Context) {
@@ -65,95 +80,160 @@ func (h *Handler) uploadDocument(c *gin.Context) {
 		return
 	}
 
+	grants, err := parseGrants(inp.Grants)
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
 	userId := getUserIdByContext(c)
 
-	var fileName string
-	var filePath string
+	var file *domain.File
 	if inp.IsFile {
-		file, err := c.FormFile("file")
+		fileHeader, err := c.FormFile("file")
 		if err != nil {
 			errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrFileNotFound.Error())
 
 			return
 		}
 
-		if file.Size > h.config.HTTPServer.MaxFileSizeMb<<20 {
+		if fileHeader.Size > h.config.HTTPServer.MaxFileSizeMb<<20 {
 			errResponse(c, http.StatusBadRequest, domain.ErrFileIsTooLarge.Error(), domain.ErrFileIsTooLarge.Error())
 
 			return
 		}
 
-		filePath = h.filePathGenerator(userId, file.Filename)
-
-		if fileExists(filePath) {
-			errResponse(c, http.StatusBadRequest, domain.ErrFileThisNameIsAlready.Error(), domain.ErrFileThisNameIsAlready.Error())
+		content, err := fileHeader.Open()
+		if err != nil {
+			errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrFileNotFound.Error())
 
 			return
 		}
+		defer content.Close()
 
-		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrInternalServerError.Error())
-
-			return
+		file = &domain.File{
+			Name:    fileHeader.Filename,
+			Size:    fileHeader.Size,
+			Content: content,
 		}
-
-		fileName = file.Filename
 	}
 
-	if err := h.service.Document.Create(&domain.Document{
+	document := &domain.Document{
 		Name:         inp.Name,
 		Mime:         inp.Mime,
-		FilePath:     filePath,
 		IsFile:       inp.IsFile,
 		IsPublic:     inp.IsPublic,
 		DocumentData: inp.DocumentData,
-		Grants:       inp.Grants,
-	}, userId); err != nil {
-		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
-
-		if inp.IsFile {
-			if err = os.Remove(filePath); err != nil {
-				logger.Errorf("failed to delete file: %v", err)
-			}
+		Type:         inp.Type,
+		Grants:       grants,
+	}
+
+	if inp.FolderId != "" {
+		document.FolderId = &inp.FolderId
+	}
+
+	unknownGrants, err := h.service.Document.Create(document, file, userId)
+	if err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrFolderNotFound) || errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidJSON) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrMimeNotAllowed) {
+			errResponse(c, http.StatusUnsupportedMediaType, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
 		}
 
 		return
 	}
 
+	var fileName string
+	if file != nil {
+		fileName = file.Name
+	}
+
 	newResponse(c, http.StatusOK, uploadDocumentData{
-		DocumentData: inp.DocumentData,
-		File:         fileName,
+		Id:            document.Id,
+		DocumentData:  inp.DocumentData,
+		File:          fileName,
+		UnknownGrants: unknownGrants,
 	}, nil)
 }
 
 type getDocumentsData struct {
-	Documents *[]domain.Document `json:"docs"`
+	Documents  *[]domain.Document `json:"docs"`
+	Folders    []domain.Folder    `json:"folders,omitempty"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	HasMore    bool               `json:"has_more"`
+	Total      *int               `json:"total,omitempty"`
+}
+
+func newDocumentsData(list *domain.DocumentList, folders []domain.Folder) getDocumentsData {
+	return getDocumentsData{
+		Documents:  list.Documents,
+		Folders:    folders,
+		NextCursor: list.NextCursor,
+		HasMore:    list.HasMore,
+		Total:      list.Total,
+	}
 }
 
 // @Summary Get documents
 // @Security UsersAuth
 // @Tags docs
-// @Description Get documents by user
+// @Description Get documents by user. If the folder is passed, documents and subfolders of the folder are returned
 // @ModuleID getDocuments
 // @Accept json
 // @Produce json
 // @Param login query string false "User login"
-// @Param key query string false "Key for filter"
+// @Param folder query string false "Folder ID"
+// @Param filter query []string false "Filters field:operator:value combined with AND. Fields: name, mime, mime_mismatch, is_file, is_public, version, created_at, updated_at or json.key.key for the JSON data. Operators: eq, ne, like, gt, lt, in (values separated by commas)" collectionFormat(multi)
+// @Param sort query string false "Sort fields separated by commas, -field for the descending order"
+// @Param key query string false "Key for filter, deprecated: use filter=key:like:value"
 // @Param value query string false "Value for filter"
 // @Param limit query int false "Limit for pagination"
-// @Param page query int false "Page for pagination"
+// @Param page query int false "Page for pagination, ignored if the cursor is passed"
+// @Param cursor query string false "next_cursor of the previous page, valid only with the same sort"
+// @Param total query bool false "Count all documents matching the filters"
 // @Success 200 {object} swagData{data=getDocumentsData} "Documents list"
 // @Failure 400 {object} swagError "Bad Request"
-// @Failure 404 {object} swagError "User not found"
+// @Failure 404 {object} swagError "User or folder not found"
 // @Failure 500 {object} swagError "Internal Server Error"
 // @Router /docs [get]
 func (h *Handler) getDocuments(c *gin.Context) {
 	filterParams := domain.PrepareFillterParams(c.Query("key"), c.Query("value"), c.Query("limit"), c.Query("page"))
 
+	if err := filterParams.SetQuery(c.QueryArray("filter"), c.Query("sort")); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	if err := filterParams.SetCursor(c.Query("cursor")); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	filterParams.Total, _ = strconv.ParseBool(c.Query("total"))
+
+	if folderId := c.Query("folder"); folderId != "" {
+		h.getFolderDocuments(c, folderId, filterParams)
+
+		return
+	}
+
 	documents, err := h.service.Document.GetByUser(c.Query("login"), getUserIdByContext(c), filterParams)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidCursor) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
 		} else {
 			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
 		}
@@ -161,22 +241,60 @@ func (h *Handler) getDocuments(c *gin.Context) {
 		return
 	}
 
-	newResponse(c, http.StatusOK, getDocumentsData{
-		Documents: documents,
-	}, nil)
+	newResponse(c, http.StatusOK, newDocumentsData(documents, nil), nil)
+}
+
+func (h *Handler) getFolderDocuments(c *gin.Context, folderId string, filterParams *domain.FilterParams) {
+	userId := getUserIdByContext(c)
+
+	folders, err := h.service.Folder.GetChildren(folderId, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	documents, err := h.service.Document.GetByFolder(folderId, userId, filterParams)
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidCursor) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, newDocumentsData(documents, folders), nil)
 }
 
 // @Summary Get document by ID
 // @Security UsersAuth
 // @Tags docs
-// @Description Get document by ID
+// @Description Get document by ID. The response has a strong ETag and Last-Modified, If-None-Match and If-Modified-Since return 304 if the document is unchanged. The ETag of a JSON document is used in If-Match of PATCH /docs/{id}/json. Files support Range with one or several ranges (multipart/byteranges) and If-Range to resume downloads
 // @ModuleID getDocument
 // @Accept json
 // @Produce json
 // @Param id path string true "Document ID"
+// @Param If-None-Match header string false "ETags of cached copies"
+// @Param If-Modified-Since header string false "Date of the cached copy"
+// @Param Range header string false "Byte ranges of the file: bytes=0-1023,-512"
+// @Param If-Range header string false "ETag or Last-Modified of the partially downloaded file"
 // @Success 200 {object} swagData{data=domain.Document} "Document"
+// @Header 200,304 {string} ETag "SHA-256 digest of the file or of the JSON document"
+// @Header 200,304 {string} Last-Modified "Time the document was updated"
+// @Success 206 {file} file "Requested ranges of the file"
+// @Header 206 {string} Content-Range "Range of a single range response"
+// @Success 304 {object} nil "Not modified"
 // @Failure 400 {object} swagError "Bad Request"
 // @Failure 404 {object} swagError "Document not found"
+// @Failure 416 {object} swagError "Range not satisfiable"
 // @Failure 500 {object} swagError "Internal Server Error"
 // @Router /docs/{id} [get]
 func (h *Handler) getDocument(c *gin.Context) {
@@ -199,30 +317,78 @@ func (h *Handler) getDocument(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, document) {
+		c.AbortWithStatus(http.StatusNotModified)
+
+		return
+	}
+
 	if !document.IsFile {
-		newResponse(c, http.StatusOK, document, nil)
+		body, err := documentBody(document)
+		if err != nil {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+			return
+		}
+
+		c.Data(http.StatusOK, jsonContentType, body)
 
 		return
 	}
 
-	if !fileExists(document.FilePath) {
-		errResponse(c, http.StatusNotFound, domain.ErrFileIsDamagedOrNotFound.Error(), domain.ErrFileIsDamagedOrNotFound.Error())
+	c.Header("Accept-Ranges", "bytes")
+
+	if header := c.GetHeader("Range"); header != "" && rangeApplies(c, document) {
+		info, err := h.service.Document.StatFile(document.FilePath)
+		if err != nil {
+			fileErrResponse(c, err)
+
+			return
+		}
+
+		ranges, err := parseRange(header, info.Size)
+		if err != nil {
+			c.Header("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			errResponse(c, http.StatusRequestedRangeNotSatisfiable, err.Error(), err.Error())
+
+			return
+		}
+
+		if len(ranges) > 0 {
+			h.serveFileRanges(c, document, info.Size, ranges)
+
+			return
+		}
+	}
+
+	file, info, err := h.service.Document.OpenFile(document.FilePath)
+	if err != nil {
+		fileErrResponse(c, err)
 
 		return
 	}
+	defer file.Close()
 
-	c.File(document.FilePath)
+	c.DataFromReader(http.StatusOK, info.Size, fileContentType(document.Mime), file, nil)
 }
 
 // @Summary Check document by ID
 // @Security UsersAuth
 // @Tags docs
-// @Description Check document by ID
+// @Description Check document by ID, the response has the headers of GET /docs/{id} without the body
 // @ModuleID checkDocument
 // @Accept json
 // @Produce json
 // @Param id path string true "Document ID"
+// @Param If-None-Match header string false "ETags of cached copies"
+// @Param If-Modified-Since header string false "Date of the cached copy"
 // @Success 200 {object} nil "Success"
+// @Header 200 {string} Content-Type "Mime of the file or application/json"
+// @Header 200 {integer} Content-Length "Size of the body GET would return"
+// @Header 200 {string} Accept-Ranges "bytes for files"
+// @Header 200,304 {string} ETag "SHA-256 digest of the file or of the JSON document"
+// @Header 200,304 {string} Last-Modified "Time the document was updated"
+// @Success 304 {object} nil "Not modified"
 // @Failure 400 {object} swagError "Bad Request"
 // @Failure 404 {object} swagError "Document not found"
 // @Failure 500 {object} swagError "Internal Server Error"
@@ -236,7 +402,7 @@ func (h *Handler) checkDocument(c *gin.Context) {
 		return
 	}
 
-	exists, err := h.service.Document.CheckById(documentId, getUserIdByContext(c))
+	document, err := h.service.Document.GetById(documentId, getUserIdByContext(c))
 	if err != nil {
 		if errors.Is(err, domain.ErrDocumentNotFound) {
 			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
@@ -247,13 +413,269 @@ func (h *Handler) checkDocument(c *gin.Context) {
 		return
 	}
 
-	if !exists {
-		errResponse(c, http.StatusNotFound, domain.ErrDocumentNotFound.Error(), domain.ErrDocumentNotFound.Error())
+	if notModified(c, document) {
+		c.AbortWithStatus(http.StatusNotModified)
 
 		return
 	}
 
-	newResponse(c, http.StatusOK, nil, nil)
+	if !document.IsFile {
+		body, err := documentBody(document)
+		if err != nil {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+			return
+		}
+
+		c.Header("Content-Type", jsonContentType)
+		c.Header("Content-Length", strconv.Itoa(len(body)))
+		c.AbortWithStatus(http.StatusOK)
+
+		return
+	}
+
+	info, err := h.service.Document.StatFile(document.FilePath)
+	if err != nil {
+		fileErrResponse(c, err)
+
+		return
+	}
+
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("Content-Type", fileContentType(document.Mime))
+	c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+	c.AbortWithStatus(http.StatusOK)
+}
+
+// notModified - sets the validators of the document and checks the conditional headers,
+// returns true if the copy of the client is still valid. If-None-Match takes precedence
+func notModified(c *gin.Context, document *domain.Document) bool {
+	etag := document.ETag()
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", document.UpdatedAt.UTC().Format(http.TimeFormat))
+	// Documents are private, caches have to revalidate them on every use
+	c.Header("Cache-Control", "private, no-cache")
+
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		return domain.MatchETagWeak(header, etag)
+	}
+
+	if header := c.GetHeader("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+
+		return err == nil && !document.UpdatedAt.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// documentBody - returns the response body of the JSON document, its length is reported by HEAD
+func documentBody(document *domain.Document) ([]byte, error) {
+	return json.Marshal(Response{
+		Data: document,
+	})
+}
+
+type updateDocumentInp struct {
+	Name         *string   `json:"name" form:"name"`
+	Mime         *string   `json:"mime" form:"mime"`
+	IsPublic     *bool     `json:"public" form:"public"`
+	Grants       *[]string `json:"grant" form:"grant[]"`
+	DocumentData *string   `json:"json" form:"json"`
+	Type         *string   `json:"type" form:"type"`
+	FolderId     *string   `json:"folder" form:"folder"`
+}
+
+type updateDocumentData struct {
+	UnknownGrants []string `json:"unknown_grants,omitempty"`
+}
+
+func (u *updateDocumentInp) validate() error {
+	if u.Name == nil && u.Mime == nil && u.IsPublic == nil && u.Grants == nil && u.DocumentData == nil &&
+		u.Type == nil && u.FolderId == nil {
+		return domain.ErrNothingToUpdate
+	}
+
+	if u.Name != nil && *u.Name == "" {
+		return domain.ErrNameIsEmpty
+	}
+
+	if u.DocumentData != nil && *u.DocumentData != "" && !json.Valid([]byte(*u.DocumentData)) {
+		return domain.ErrInvalidJSON
+	}
+
+	return nil
+}
+
+// @Summary Update document metadata
+// @Security UsersAuth
+// @Tags docs
+// @Description Update document metadata, only passed fields are changed. Grants are replaced entirely, empty folder moves the document to the root. Empty json or type removes the data or the schema, data of a document with a schema is validated against it. Requires the write permission, changing grants or visibility requires the share permission, moving to another folder is left to the owner
+// @ModuleID updateDocument
+// @Accept json,multipart/form-data
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param input body updateDocumentInp true "Document metadata"
+// @Success 200 {object} Response{data=updateDocumentData,response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request, details list fields that don't match the schema"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document, folder or schema not found"
+// @Failure 415 {object} swagError "File type is not allowed"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id} [patch]
+func (h *Handler) updateDocument(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp updateDocumentInp
+	if err := c.ShouldBind(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrInvalidMetaData.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	var grants *[]domain.Grant
+	if inp.Grants != nil {
+		parsed, err := parseGrants(*inp.Grants)
+		if err != nil {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+			return
+		}
+
+		grants = &parsed
+	}
+
+	unknownGrants, err := h.service.Document.Update(documentId, getUserIdByContext(c), &domain.DocumentUpdate{
+		Name:         inp.Name,
+		Mime:         inp.Mime,
+		IsPublic:     inp.IsPublic,
+		Grants:       grants,
+		DocumentData: inp.DocumentData,
+		Type:         inp.Type,
+		FolderId:     inp.FolderId,
+	})
+	if err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrDocumentNotFound) || errors.Is(err, domain.ErrFolderNotFound) ||
+			errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrMimeNotAllowed) {
+			errResponse(c, http.StatusUnsupportedMediaType, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	var data interface{}
+	if len(unknownGrants) > 0 {
+		data = updateDocumentData{UnknownGrants: unknownGrants}
+	}
+
+	newResponse(c, http.StatusOK, data, map[string]bool{
+		documentId: true,
+	})
+}
+
+type replaceDocumentFileInp struct {
+	Mime string `form:"mime"`
+}
+
+// @Summary Replace document file
+// @Security UsersAuth
+// @Tags docs
+// @Description Replace file content of the document. Requires the write permission
+// @ModuleID replaceDocumentFile
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param mime formData string false "Document mime type, checked against the file content like on the upload"
+// @Param file formData file true "Document file"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 415 {object} swagError "File type is not allowed"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id} [put]
+func (h *Handler) replaceDocumentFile(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp replaceDocumentFileInp
+	if err := c.ShouldBind(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrInvalidMetaData.Error())
+
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrFileNotFound.Error())
+
+		return
+	}
+
+	if fileHeader.Size > h.config.HTTPServer.MaxFileSizeMb<<20 {
+		errResponse(c, http.StatusBadRequest, domain.ErrFileIsTooLarge.Error(), domain.ErrFileIsTooLarge.Error())
+
+		return
+	}
+
+	content, err := fileHeader.Open()
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrFileNotFound.Error())
+
+		return
+	}
+	defer content.Close()
+
+	if err := h.service.Document.UpdateFile(documentId, getUserIdByContext(c), &domain.File{
+		Name:    fileHeader.Filename,
+		Size:    fileHeader.Size,
+		Content: content,
+	}, inp.Mime); err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrDocumentIsNotFile) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrMimeNotAllowed) {
+			errResponse(c, http.StatusUnsupportedMediaType, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		documentId: true,
+	})
 }
 
 // @Summary Delete document by ID
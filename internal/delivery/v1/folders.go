@@ -0,0 +1,411 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type createFolderInp struct {
+	Name     string  `json:"name"`
+	ParentId *string `json:"parent_id"`
+}
+
+func (f *createFolderInp) validate() error {
+	if f.Name == "" {
+		return domain.ErrNameIsEmpty
+	}
+
+	if f.ParentId != nil && *f.ParentId == "" {
+		f.ParentId = nil
+	}
+
+	return nil
+}
+
+type createFolderData struct {
+	Id string `json:"id"`
+}
+
+// updateFolderInp - empty parent_id moves the folder to the root
+type updateFolderInp struct {
+	Name     *string `json:"name"`
+	ParentId *string `json:"parent_id"`
+}
+
+func (f *updateFolderInp) validate() error {
+	if f.Name == nil && f.ParentId == nil {
+		return domain.ErrNothingToUpdate
+	}
+
+	if f.Name != nil && *f.Name == "" {
+		return domain.ErrNameIsEmpty
+	}
+
+	return nil
+}
+
+type getFoldersData struct {
+	Folders []domain.Folder `json:"folders"`
+}
+
+// @Summary Create folder
+// @Security UsersAuth
+// @Tags folders
+// @Description Create folder, subfolders can be created only in own folders
+// @ModuleID createFolder
+// @Accept json
+// @Produce json
+// @Param input body createFolderInp true "Folder"
+// @Success 200 {object} swagData{data=createFolderData} "Folder created"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Parent folder not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders [post]
+func (h *Handler) createFolder(c *gin.Context) {
+	var inp createFolderInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	folder := &domain.Folder{
+		Name:     inp.Name,
+		ParentId: inp.ParentId,
+	}
+
+	if err := h.service.Folder.Create(folder, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, createFolderData{
+		Id: folder.Id,
+	}, nil)
+}
+
+// @Summary Get folders
+// @Security UsersAuth
+// @Tags folders
+// @Description Get all folders of the user, the tree is built by parent_id. Folder contents are listed by /docs?folder={id}
+// @ModuleID getFolders
+// @Accept json
+// @Produce json
+// @Success 200 {object} swagData{data=getFoldersData} "Folders list"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders [get]
+func (h *Handler) getFolders(c *gin.Context) {
+	folders, err := h.service.Folder.GetByUser(getUserIdByContext(c))
+	if err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getFoldersData{
+		Folders: folders,
+	}, nil)
+}
+
+// @Summary Get folder by ID
+// @Security UsersAuth
+// @Tags folders
+// @Description Get folder by ID
+// @ModuleID getFolder
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} swagData{data=domain.Folder} "Folder"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id} [get]
+func (h *Handler) getFolder(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	folder, err := h.service.Folder.GetById(folderId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, folder, nil)
+}
+
+// @Summary Update folder
+// @Security UsersAuth
+// @Tags folders
+// @Description Rename or move folder, empty parent_id moves the folder to the root. Only the owner can change the folder
+// @ModuleID updateFolder
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param input body updateFolderInp true "Folder changes"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 409 {object} swagError "Folder can't be moved into itself"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id} [patch]
+func (h *Handler) updateFolder(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp updateFolderInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	if err := h.service.Folder.Update(folderId, getUserIdByContext(c), &domain.FolderUpdate{
+		Name:     inp.Name,
+		ParentId: inp.ParentId,
+	}); err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrFolderCycle) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		folderId: true,
+	})
+}
+
+// @Summary Delete folder
+// @Security UsersAuth
+// @Tags folders
+// @Description Delete empty folder. Only the owner can delete the folder
+// @ModuleID deleteFolder
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 409 {object} swagError "Folder is not empty"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id} [delete]
+func (h *Handler) deleteFolder(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.Folder.Delete(folderId, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrFolderIsNotEmpty) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		folderId: true,
+	})
+}
+
+// @Summary Get folder grants
+// @Security UsersAuth
+// @Tags folders
+// @Description Get users and groups who have access to the folder, grants of parent folders aren't included
+// @ModuleID getFolderGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} swagData{data=getGrantsData} "Grants list"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id}/grants [get]
+func (h *Handler) getFolderGrants(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	grants, err := h.service.Folder.GetGrants(folderId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getGrantsData{
+		Grants: grants,
+	}, nil)
+}
+
+// @Summary Add folder grants
+// @Security UsersAuth
+// @Tags folders
+// @Description Grant access to the folder, documents in it and in its subfolders inherit the grant. Groups are passed as @group. Requires the share permission, unknown logins and groups are returned back
+// @ModuleID addFolderGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param input body addGrantsInp true "Logins, groups and permission"
+// @Success 200 {object} swagResponse{response=addGrantsResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id}/grants [post]
+func (h *Handler) addFolderGrants(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp addGrantsInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Folder.AddGrants(folderId, getUserIdByContext(c), inp.grants())
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, addGrantsResponse{
+		Granted: excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
+
+// @Summary Delete folder grants
+// @Security UsersAuth
+// @Tags folders
+// @Description Revoke access to the folder. Groups are passed as @group. Requires the share permission, unknown logins and groups are returned back
+// @ModuleID deleteFolderGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param input body grantsInp true "Logins and groups"
+// @Success 200 {object} swagResponse{response=deleteGrantsResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Folder not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /folders/{id}/grants [delete]
+func (h *Handler) deleteFolderGrants(c *gin.Context) {
+	folderId := c.Param("id")
+
+	if folderId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp grantsInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Folder.DeleteGrants(folderId, getUserIdByContext(c), inp.Logins)
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, deleteGrantsResponse{
+		Revoked: excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+// grantsInp - logins of users, groups are passed as "@group"
+type grantsInp struct {
+	Logins []string `json:"logins"`
+}
+
+func (g *grantsInp) validate() error {
+	g.Logins = cleanLogins(g.Logins)
+	if len(g.Logins) == 0 {
+		return domain.ErrLoginsIsEmpty
+	}
+
+	return nil
+}
+
+// addGrantsInp - logins of users, groups are passed as "@group"
+type addGrantsInp struct {
+	Logins     []string          `json:"logins"`
+	Permission domain.Permission `json:"permission" enums:"read,write,share" default:"read"`
+}
+
+func (a *addGrantsInp) validate() error {
+	a.Logins = cleanLogins(a.Logins)
+	if len(a.Logins) == 0 {
+		return domain.ErrLoginsIsEmpty
+	}
+
+	if a.Permission == "" {
+		a.Permission = domain.PermissionRead
+	}
+
+	if !a.Permission.Grantable() {
+		return domain.ErrInvalidPermission
+	}
+
+	return nil
+}
+
+func (a *addGrantsInp) grants() []domain.Grant {
+	grants := make([]domain.Grant, 0, len(a.Logins))
+	for _, login := range a.Logins {
+		grants = append(grants, domain.NewGrant(login, a.Permission))
+	}
+
+	return grants
+}
+
+type getGrantsData struct {
+	Grants []domain.Grant `json:"grants"`
+}
+
+type addGrantsResponse struct {
+	Granted []string `json:"granted"`
+	Unknown []string `json:"unknown"`
+}
+
+type deleteGrantsResponse struct {
+	Revoked []string `json:"revoked"`
+	Unknown []string `json:"unknown"`
+}
+
+// @Summary Get document grants
+// @Security UsersAuth
+// @Tags grants
+// @Description Get users and groups who have access to the document and their permissions
+// @ModuleID getGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} swagData{data=getGrantsData} "Grants list"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/grants [get]
+func (h *Handler) getGrants(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	grants, err := h.service.Document.GetGrants(documentId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getGrantsData{
+		Grants: grants,
+	}, nil)
+}
+
+// @Summary Add document grants
+// @Security UsersAuth
+// @Tags grants
+// @Description Grant access to the document or change the permission of existing grants. Groups are passed as @group. Requires the share permission, unknown logins and groups are returned back
+// @ModuleID addGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param input body addGrantsInp true "Logins, groups and permission"
+// @Success 200 {object} swagResponse{response=addGrantsResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/grants [post]
+func (h *Handler) addGrants(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp addGrantsInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Document.AddGrants(documentId, getUserIdByContext(c), inp.grants())
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, addGrantsResponse{
+		Granted: excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
+
+// @Summary Delete document grants
+// @Security UsersAuth
+// @Tags grants
+// @Description Revoke access to the document. Requires the share permission, the owner's access can't be revoked. Groups are passed as @group, unknown logins and groups are returned back
+// @ModuleID deleteGrants
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param input body grantsInp true "Logins and groups"
+// @Success 200 {object} swagResponse{response=deleteGrantsResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/grants [delete]
+func (h *Handler) deleteGrants(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp grantsInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Document.DeleteGrants(documentId, getUserIdByContext(c), inp.Logins)
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, deleteGrantsResponse{
+		Revoked: excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
@@ -0,0 +1,384 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type createGroupInp struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+func (g *createGroupInp) validate() error {
+	if !validateGroupName(g.Name) {
+		return domain.ErrInvalidGroupName
+	}
+
+	g.Members = cleanLogins(g.Members)
+
+	return nil
+}
+
+type createGroupData struct {
+	Id             string   `json:"id"`
+	UnknownMembers []string `json:"unknown_members,omitempty"`
+}
+
+type renameGroupInp struct {
+	Name string `json:"name"`
+}
+
+func (g *renameGroupInp) validate() error {
+	if !validateGroupName(g.Name) {
+		return domain.ErrInvalidGroupName
+	}
+
+	return nil
+}
+
+type membersInp struct {
+	Logins []string `json:"logins"`
+}
+
+func (m *membersInp) validate() error {
+	m.Logins = cleanLogins(m.Logins)
+	if len(m.Logins) == 0 {
+		return domain.ErrLoginsIsEmpty
+	}
+
+	return nil
+}
+
+type getGroupsData struct {
+	Groups []domain.Group `json:"groups"`
+}
+
+type addMembersResponse struct {
+	Added   []string `json:"added"`
+	Unknown []string `json:"unknown"`
+}
+
+type deleteMembersResponse struct {
+	Removed []string `json:"removed"`
+	Unknown []string `json:"unknown"`
+}
+
+// @Summary Create group
+// @Security UsersAuth
+// @Tags groups
+// @Description Create a group of users, documents can be granted to it as @name. The creator owns the group and becomes its member, unknown logins are returned back
+// @ModuleID createGroup
+// @Accept json
+// @Produce json
+// @Param input body createGroupInp true "Group"
+// @Success 200 {object} swagData{data=createGroupData} "Group created"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 409 {object} swagError "Group name is busy"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups [post]
+func (h *Handler) createGroup(c *gin.Context) {
+	var inp createGroupInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	group := &domain.Group{
+		Name: inp.Name,
+	}
+
+	unknown, err := h.service.Group.Create(group, getUserIdByContext(c), inp.Members)
+	if err != nil {
+		if errors.Is(err, domain.ErrGroupNameIsBusy) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, createGroupData{
+		Id:             group.Id,
+		UnknownMembers: unknown,
+	}, nil)
+}
+
+// @Summary Get groups
+// @Security UsersAuth
+// @Tags groups
+// @Description Get groups the user is a member of
+// @ModuleID getGroups
+// @Accept json
+// @Produce json
+// @Success 200 {object} swagData{data=getGroupsData} "Groups list"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups [get]
+func (h *Handler) getGroups(c *gin.Context) {
+	groups, err := h.service.Group.GetByUser(getUserIdByContext(c))
+	if err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getGroupsData{
+		Groups: groups,
+	}, nil)
+}
+
+// @Summary Get group by ID
+// @Security UsersAuth
+// @Tags groups
+// @Description Get group with its members, only members can see the group
+// @ModuleID getGroup
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} swagData{data=domain.Group} "Group"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Group not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups/{id} [get]
+func (h *Handler) getGroup(c *gin.Context) {
+	groupId := c.Param("id")
+
+	if groupId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	group, err := h.service.Group.GetById(groupId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, group, nil)
+}
+
+// @Summary Rename group
+// @Security UsersAuth
+// @Tags groups
+// @Description Rename group, existing grants keep working. Only the owner can rename the group
+// @ModuleID renameGroup
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param input body renameGroupInp true "Group name"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Group not found"
+// @Failure 409 {object} swagError "Group name is busy"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups/{id} [patch]
+func (h *Handler) renameGroup(c *gin.Context) {
+	groupId := c.Param("id")
+
+	if groupId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp renameGroupInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	if err := h.service.Group.Rename(groupId, getUserIdByContext(c), inp.Name); err != nil {
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrGroupNameIsBusy) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		groupId: true,
+	})
+}
+
+// @Summary Delete group
+// @Security UsersAuth
+// @Tags groups
+// @Description Delete group, documents granted to it are no longer available to its members. Only the owner can delete the group
+// @ModuleID deleteGroup
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Group not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups/{id} [delete]
+func (h *Handler) deleteGroup(c *gin.Context) {
+	groupId := c.Param("id")
+
+	if groupId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.Group.Delete(groupId, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		groupId: true,
+	})
+}
+
+// @Summary Add group members
+// @Security UsersAuth
+// @Tags groups
+// @Description Add users to the group. Only the owner can manage members, unknown logins are returned back
+// @ModuleID addGroupMembers
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param input body membersInp true "Logins"
+// @Success 200 {object} swagResponse{response=addMembersResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Group not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups/{id}/members [post]
+func (h *Handler) addGroupMembers(c *gin.Context) {
+	groupId := c.Param("id")
+
+	if groupId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp membersInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Group.AddMembers(groupId, getUserIdByContext(c), inp.Logins)
+	if err != nil {
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, addMembersResponse{
+		Added:   excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
+
+// @Summary Delete group members
+// @Security UsersAuth
+// @Tags groups
+// @Description Remove users from the group. Only the owner can manage members, the owner can't be removed, unknown logins are returned back
+// @ModuleID deleteGroupMembers
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param input body membersInp true "Logins"
+// @Success 200 {object} swagResponse{response=deleteMembersResponse} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Group not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /groups/{id}/members [delete]
+func (h *Handler) deleteGroupMembers(c *gin.Context) {
+	groupId := c.Param("id")
+
+	if groupId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp membersInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	unknown, err := h.service.Group.DeleteMembers(groupId, getUserIdByContext(c), inp.Logins)
+	if err != nil {
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, deleteMembersResponse{
+		Removed: excludeLogins(inp.Logins, unknown),
+		Unknown: unknown,
+	})
+}
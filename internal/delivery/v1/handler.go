@@ -30,15 +30,78 @@ func (h *Handler) Init(router *gin.RouterGroup) {
 	auth := router.Group("/auth")
 	{
 		auth.POST("", h.authUser)
-		auth.DELETE("/:token", h.deleteSession)
+		auth.POST("/refresh", h.refreshTokens)
+		auth.GET("/sessions", h.middlewareAuth, h.getSessions)
+		auth.DELETE("/sessions", h.middlewareAuth, h.deleteSessions)
+		auth.DELETE("/sessions/:id", h.middlewareAuth, h.deleteSessionById)
+		auth.DELETE("/:token", h.middlewareAuth, h.deleteSession)
 	}
 
 	docs := router.Group("/docs", h.middlewareAuth)
 	{
 		docs.POST("", h.uploadDocument)
 		docs.GET("", h.getDocuments)
+		docs.GET("/search", h.searchDocuments)
 		docs.GET("/:id", h.getDocument)
 		docs.HEAD("/:id", h.checkDocument)
+		docs.PATCH("/:id", h.updateDocument)
+		docs.PATCH("/:id/json", h.patchDocumentData)
+		docs.PUT("/:id", h.replaceDocumentFile)
 		docs.DELETE("/:id", h.deleteDocument)
+		docs.GET("/:id/versions", h.getDocumentVersions)
+		docs.GET("/:id/versions/:n", h.getDocumentVersion)
+		docs.POST("/:id/versions/:n/restore", h.restoreDocumentVersion)
+		docs.GET("/:id/grants", h.getGrants)
+		docs.POST("/:id/grants", h.addGrants)
+		docs.DELETE("/:id/grants", h.deleteGrants)
+		docs.POST("/:id/links", h.createShareLink)
+		docs.GET("/:id/links", h.getShareLinks)
+		docs.DELETE("/:id/links/:linkId", h.deleteShareLink)
+	}
+
+	groups := router.Group("/groups", h.middlewareAuth)
+	{
+		groups.POST("", h.createGroup)
+		groups.GET("", h.getGroups)
+		groups.GET("/:id", h.getGroup)
+		groups.PATCH("/:id", h.renameGroup)
+		groups.DELETE("/:id", h.deleteGroup)
+		groups.POST("/:id/members", h.addGroupMembers)
+		groups.DELETE("/:id/members", h.deleteGroupMembers)
+	}
+
+	folders := router.Group("/folders", h.middlewareAuth)
+	{
+		folders.POST("", h.createFolder)
+		folders.GET("", h.getFolders)
+		folders.GET("/:id", h.getFolder)
+		folders.PATCH("/:id", h.updateFolder)
+		folders.DELETE("/:id", h.deleteFolder)
+		folders.GET("/:id/grants", h.getFolderGrants)
+		folders.POST("/:id/grants", h.addFolderGrants)
+		folders.DELETE("/:id/grants", h.deleteFolderGrants)
+	}
+
+	schemas := router.Group("/schemas", h.middlewareAuth)
+	{
+		schemas.POST("", h.createSchema)
+		schemas.GET("", h.getSchemas)
+		schemas.GET("/:name", h.getSchema)
+		schemas.PUT("/:name", h.updateSchema)
+		schemas.DELETE("/:name", h.deleteSchema)
+	}
+
+	uploads := router.Group("/uploads", h.middlewareAuth)
+	{
+		uploads.POST("", h.createUpload)
+		uploads.GET("/:id", h.getUpload)
+		uploads.PATCH("/:id", h.writeUploadChunk)
+		uploads.POST("/:id/complete", h.completeUpload)
+		uploads.DELETE("/:id", h.deleteUpload)
+	}
+
+	shared := router.Group("/shared")
+	{
+		shared.GET("/:token", h.getSharedDocument)
 	}
 }
@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type createShareLinkInp struct {
+	// ExpiresIn - lifetime of the link in seconds, 0 - default lifetime
+	ExpiresIn    int  `json:"expires_in"`
+	MaxDownloads *int `json:"max_downloads"`
+}
+
+func (l *createShareLinkInp) validate() error {
+	if l.ExpiresIn < 0 {
+		return domain.ErrInvalidExpiration
+	}
+
+	if l.MaxDownloads != nil && *l.MaxDownloads <= 0 {
+		return domain.ErrInvalidMaxDownloads
+	}
+
+	return nil
+}
+
+type getShareLinksData struct {
+	Links []domain.ShareLink `json:"links"`
+}
+
+// sharedDocumentData - document for holders of the share link, grants and placement of the document aren't disclosed
+type sharedDocumentData struct {
+	Name         string    `json:"name"`
+	Mime         string    `json:"mime"`
+	DocumentData string    `json:"json,omitempty"`
+	CreatedAt    time.Time `json:"created"`
+	UpdatedAt    time.Time `json:"updated"`
+}
+
+func newSharedDocumentData(document *domain.Document) sharedDocumentData {
+	return sharedDocumentData{
+		Name:         document.Name,
+		Mime:         document.Mime,
+		DocumentData: document.DocumentData,
+		CreatedAt:    document.CreatedAt,
+		UpdatedAt:    document.UpdatedAt,
+	}
+}
+
+// @Summary Create share link
+// @Security UsersAuth
+// @Tags links
+// @Description Create a link that gives access to the document without authorization, the link is resolved by /shared/{token}. The token is returned only here. Requires the share permission
+// @ModuleID createShareLink
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param input body createShareLinkInp false "Link options"
+// @Success 200 {object} swagData{data=domain.ShareLink} "Share link"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/links [post]
+func (h *Handler) createShareLink(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	// All options are optional, so is the body
+	var inp createShareLinkInp
+	if err := c.ShouldBindJSON(&inp); err != nil && !errors.Is(err, io.EOF) {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	link, err := h.service.Document.CreateShareLink(documentId, getUserIdByContext(c),
+		time.Duration(inp.ExpiresIn)*time.Second, inp.MaxDownloads)
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidExpiration) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, link, nil)
+}
+
+// @Summary Get share links
+// @Security UsersAuth
+// @Tags links
+// @Description Get share links of the document, including expired ones. Tokens aren't returned, they are stored as hashes. Requires the share permission
+// @ModuleID getShareLinks
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} swagData{data=getShareLinksData} "Share links"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/links [get]
+func (h *Handler) getShareLinks(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	links, err := h.service.Document.GetShareLinks(documentId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getShareLinksData{
+		Links: links,
+	}, nil)
+}
+
+// @Summary Revoke share link
+// @Security UsersAuth
+// @Tags links
+// @Description Revoke the share link, the token stops working immediately. Requires the share permission
+// @ModuleID deleteShareLink
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param linkId path string true "Link ID"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Link not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/links/{linkId} [delete]
+func (h *Handler) deleteShareLink(c *gin.Context) {
+	documentId := c.Param("id")
+	linkId := c.Param("linkId")
+
+	if documentId == "" || linkId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.Document.DeleteShareLink(documentId, getUserIdByContext(c), linkId); err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) || errors.Is(err, domain.ErrShareLinkNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		linkId: true,
+	})
+}
+
+// @Summary Get shared document
+// @Tags links
+// @Description Get document by share token without authorization. Every request counts as a download
+// @ModuleID getSharedDocument
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} swagData{data=sharedDocumentData} "Document"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Link not found"
+// @Failure 410 {object} swagError "Link expired"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /shared/{token} [get]
+func (h *Handler) getSharedDocument(c *gin.Context) {
+	token := c.Param("token")
+
+	if token == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	document, err := h.service.Document.GetByShareToken(token)
+	if err != nil {
+		if errors.Is(err, domain.ErrShareLinkNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrShareLinkExpired) {
+			errResponse(c, http.StatusGone, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	if !document.IsFile {
+		newResponse(c, http.StatusOK, newSharedDocumentData(document), nil)
+
+		return
+	}
+
+	file, info, err := h.service.Document.OpenFile(document.FilePath)
+	if err != nil {
+		if errors.Is(err, domain.ErrFileIsDamagedOrNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, fileContentType(document.Mime), file, nil)
+}
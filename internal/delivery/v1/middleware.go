@@ -23,16 +23,26 @@ func (h *Handler) middlewareAuth(c *gin.Context) {
 	}
 	logger.Debugf("token=%v", token)
 
-	userId, err := h.service.User.GetUserIdByToken(token)
+	// The signature and the expiration are verified locally, the database is left to the revocation check
+	claims, err := h.tokenManager.Parse(token)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			errResponse(c, http.StatusUnauthorized, err.Error(), domain.ErrUserUnauthorized.Error())
+		errResponse(c, http.StatusUnauthorized, err.Error(), domain.ErrUserUnauthorized.Error())
 
-			return
+		return
+	}
+
+	if err := h.service.User.CheckToken(token, claims); err != nil {
+		if errors.Is(err, domain.ErrUserUnauthorized) {
+			errResponse(c, http.StatusUnauthorized, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
 		}
+
+		return
 	}
 
-	c.Set("userId", userId)
+	c.Set("userId", claims.Subject)
+	c.Set("sessionId", claims.SessionId)
 
 	c.Next()
 }
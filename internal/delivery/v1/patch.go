@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type patchDocumentData struct {
+	DocumentData json.RawMessage `json:"json" swaggertype:"object"`
+	Version      int             `json:"version"`
+}
+
+// @Summary Patch document data
+// @Security UsersAuth
+// @Tags docs
+// @Description Change the JSON data of the document with a JSON Patch (application/json-patch+json, RFC 6902) or a JSON Merge Patch (application/merge-patch+json, RFC 7396). The patch is applied atomically, If-Match with the ETag of the document rejects the patch if the document was changed since it was read. Data of a document with a schema is validated against it. Requires the write permission
+// @ModuleID patchDocumentData
+// @Accept application/json-patch+json,application/merge-patch+json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param If-Match header string false "ETag of the document"
+// @Param input body object true "JSON Patch or JSON Merge Patch"
+// @Success 200 {object} swagData{data=patchDocumentData} "Patched data, the new ETag is in the ETag header"
+// @Failure 400 {object} swagError "Bad Request, details list fields that don't match the schema"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Document or schema not found"
+// @Failure 409 {object} swagError "Patch can't be applied to the data"
+// @Failure 412 {object} swagError "Document was changed"
+// @Failure 415 {object} swagError "Unsupported patch type"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/json [patch]
+func (h *Handler) patchDocumentData(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	patchType := domain.PatchType(c.ContentType())
+	if !patchType.Valid() {
+		errResponse(c, http.StatusUnsupportedMediaType, domain.ErrUnsupportedPatch.Error(), domain.ErrUnsupportedPatch.Error())
+
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrInvalidPatch.Error())
+
+		return
+	}
+
+	document, err := h.service.Document.PatchData(documentId, getUserIdByContext(c), &domain.DataPatch{
+		Type:    patchType,
+		Patch:   body,
+		IfMatch: c.GetHeader("If-Match"),
+	})
+	if err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrDocumentNotFound) || errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidPatch) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPatchConflict) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrDocumentChanged) {
+			errResponse(c, http.StatusPreconditionFailed, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	data := json.RawMessage("null")
+	if document.DocumentData != "" {
+		data = json.RawMessage(document.DocumentData)
+	}
+
+	c.Header("ETag", document.ETag())
+	newResponse(c, http.StatusOK, patchDocumentData{
+		DocumentData: data,
+		Version:      document.Version,
+	}, nil)
+}
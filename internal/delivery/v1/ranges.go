@@ -0,0 +1,216 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// maxRanges - limit of ranges in one request, every range is a separate read from the storage
+const maxRanges = 16
+
+// byteRange - part of the file, length is positive
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseRange - parses the Range header for the file of the size. Returns nil if the header
+// can't be parsed or the ranges are larger than the file, it's ignored then. Ranges outside the file are skipped,
+// domain.ErrRangeNotSatisfiable is returned if none is left
+func parseRange(header string, size int64) ([]byteRange, error) {
+	specs, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil, nil
+	}
+
+	ranges := make([]byteRange, 0)
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, nil
+		}
+
+		var r byteRange
+		if first == "" {
+			// Suffix range: the last bytes of the file
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+
+			if n == 0 || size == 0 {
+				continue
+			}
+
+			r = byteRange{start: max(size-n, 0), length: min(n, size)}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, nil
+				}
+			}
+
+			if start >= size {
+				continue
+			}
+
+			r = byteRange{start: start, length: min(end, size-1) - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 || len(ranges) > maxRanges {
+		return nil, domain.ErrRangeNotSatisfiable
+	}
+
+	// Overlapping ranges larger than the file only multiply the traffic, the whole file is sent instead
+	var total int64
+	for _, r := range ranges {
+		total += r.length
+	}
+
+	if total > size {
+		return nil, nil
+	}
+
+	return ranges, nil
+}
+
+// rangeApplies - checks If-Range, the range is served only if the file is still the one the client has.
+// A date matches only the exact Last-Modified
+func rangeApplies(c *gin.Context, document *domain.Document) bool {
+	header := c.GetHeader("If-Range")
+	if header == "" {
+		return true
+	}
+
+	if strings.HasPrefix(header, `"`) || strings.HasPrefix(header, "W/") {
+		return header == document.ETag()
+	}
+
+	since, err := http.ParseTime(header)
+
+	return err == nil && since.Equal(document.UpdatedAt.Truncate(time.Second))
+}
+
+// serveFileRanges - writes one range as 206 with Content-Range or several ones as multipart/byteranges
+func (h *Handler) serveFileRanges(c *gin.Context, document *domain.Document, size int64, ranges []byteRange) {
+	contentType := fileContentType(document.Mime)
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+
+		file, err := h.service.Document.OpenFileRange(document.FilePath, r.start, r.length)
+		if err != nil {
+			fileErrResponse(c, err)
+
+			return
+		}
+		defer file.Close()
+
+		c.DataFromReader(http.StatusPartialContent, r.length, contentType, file, map[string]string{
+			"Content-Range": r.contentRange(size),
+		})
+
+		return
+	}
+
+	partHeader := func(r byteRange) textproto.MIMEHeader {
+		return textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {r.contentRange(size)},
+		}
+	}
+
+	// The body is written once to count its length, clients use it to see the progress
+	var counter countingWriter
+	mw := multipart.NewWriter(&counter)
+	for _, r := range ranges {
+		mw.CreatePart(partHeader(r))
+		counter += countingWriter(r.length)
+	}
+	mw.Close()
+
+	// The first range is opened before the status, so a missing file is still reported as 404
+	first, err := h.service.Document.OpenFileRange(document.FilePath, ranges[0].start, ranges[0].length)
+	if err != nil {
+		fileErrResponse(c, err)
+
+		return
+	}
+
+	c.Header("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	c.Header("Content-Length", strconv.FormatInt(int64(counter), 10))
+	c.Status(http.StatusPartialContent)
+
+	body := multipart.NewWriter(c.Writer)
+	body.SetBoundary(mw.Boundary())
+	for i, r := range ranges {
+		file := first
+		if i > 0 {
+			if file, err = h.service.Document.OpenFileRange(document.FilePath, r.start, r.length); err != nil {
+				logger.Errorf("failed to open file range: %v", err)
+				c.Abort()
+
+				return
+			}
+		}
+
+		part, err := body.CreatePart(partHeader(r))
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		file.Close()
+
+		if err != nil {
+			logger.Errorf("failed to write file range: %v", err)
+			c.Abort()
+
+			return
+		}
+	}
+	body.Close()
+}
+
+// fileErrResponse - responds to an error of reading the document file
+func fileErrResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrFileIsDamagedOrNotFound) {
+		errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+	} else {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+	}
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
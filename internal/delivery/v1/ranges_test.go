@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		want   []byteRange
+		err    error
+	}{
+		{name: "first bytes", header: "bytes=0-99", size: 1000, want: []byteRange{{start: 0, length: 100}}},
+		{name: "open end", header: "bytes=900-", size: 1000, want: []byteRange{{start: 900, length: 100}}},
+		{name: "end past the file", header: "bytes=900-5000", size: 1000, want: []byteRange{{start: 900, length: 100}}},
+		{name: "suffix", header: "bytes=-100", size: 1000, want: []byteRange{{start: 900, length: 100}}},
+		{name: "suffix larger than the file", header: "bytes=-5000", size: 1000, want: []byteRange{{start: 0, length: 1000}}},
+		{name: "zero suffix", header: "bytes=-0", size: 1000, err: domain.ErrRangeNotSatisfiable},
+		{name: "suffix of an empty file", header: "bytes=-10", size: 0, err: domain.ErrRangeNotSatisfiable},
+		{
+			name:   "several ranges",
+			header: "bytes=0-9, 20-29,-10",
+			size:   1000,
+			want:   []byteRange{{start: 0, length: 10}, {start: 20, length: 10}, {start: 990, length: 10}},
+		},
+		{name: "start at the size", header: "bytes=1000-", size: 1000, err: domain.ErrRangeNotSatisfiable},
+		{name: "start past the size", header: "bytes=2000-3000", size: 1000, err: domain.ErrRangeNotSatisfiable},
+		{
+			name:   "range outside the file is skipped",
+			header: "bytes=2000-3000,0-9",
+			size:   1000,
+			want:   []byteRange{{start: 0, length: 10}},
+		},
+		{name: "overlap larger than the file", header: "bytes=0-799,200-999", size: 1000, want: nil},
+		{name: "repeated whole file", header: "bytes=0-,0-", size: 1000, want: nil},
+		{
+			name:   "too many ranges",
+			header: "bytes=0-0,1-1,2-2,3-3,4-4,5-5,6-6,7-7,8-8,9-9,10-10,11-11,12-12,13-13,14-14,15-15,16-16",
+			size:   1000,
+			err:    domain.ErrRangeNotSatisfiable,
+		},
+		{name: "other unit", header: "items=0-9", size: 1000, want: nil},
+		{name: "end before start", header: "bytes=100-99", size: 1000, want: nil},
+		{name: "no dash", header: "bytes=100", size: 1000, want: nil},
+		{name: "negative start", header: "bytes=-1-10", size: 1000, want: nil},
+		{name: "not a number", header: "bytes=a-10", size: 1000, want: nil},
+		{name: "one malformed of several", header: "bytes=0-9,x-y", size: 1000, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRange(tt.header, tt.size)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseRange(%q, %v) error = %v, want %v", tt.header, tt.size, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRange(%q, %v) = %v, want %v", tt.header, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeApplies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 15, 500, time.UTC)
+	document := &domain.Document{
+		IsFile:    true,
+		FilePath:  "sha256/ab/abcdef",
+		UpdatedAt: updatedAt,
+	}
+
+	tests := []struct {
+		name    string
+		ifRange string
+		want    bool
+	}{
+		{name: "no header", ifRange: "", want: true},
+		{name: "matching etag", ifRange: `"abcdef"`, want: true},
+		{name: "other etag", ifRange: `"012345"`, want: false},
+		{name: "weak etag", ifRange: `W/"abcdef"`, want: false},
+		{name: "exact date", ifRange: updatedAt.Format(http.TimeFormat), want: true},
+		{name: "later date", ifRange: updatedAt.Add(time.Hour).Format(http.TimeFormat), want: false},
+		{name: "earlier date", ifRange: updatedAt.Add(-time.Hour).Format(http.TimeFormat), want: false},
+		{name: "malformed date", ifRange: "yesterday", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifRange != "" {
+				c.Request.Header.Set("If-Range", tt.ifRange)
+			}
+
+			if got := rangeApplies(c, document); got != tt.want {
+				t.Errorf("rangeApplies(If-Range: %q) = %v, want %v", tt.ifRange, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package v1
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
 	"github.com/sixojke/test-astral/pkg/logger"
 )
 
@@ -12,8 +16,9 @@ type Response struct {
 }
 
 type errorResponse struct {
-	Code int    `json:"code,omitempty"`
-	Text string `json:"text,omitempty"`
+	Code    int                 `json:"code,omitempty"`
+	Text    string              `json:"text,omitempty"`
+	Details []domain.FieldError `json:"details,omitempty"` // invalid JSON fields
 }
 
 type swagError struct {
@@ -38,6 +43,26 @@ func errResponse(с *gin.Context, statusCode int, err, errResp string) {
 	logger.Warn(err)
 }
 
+// validationErrResponse - responds 400 with invalid fields if err is *domain.ValidationError
+func validationErrResponse(c *gin.Context, err error) bool {
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) {
+		return false
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, Response{
+		Error: &errorResponse{
+			Code:    http.StatusBadRequest,
+			Text:    verr.Err.Error(),
+			Details: verr.Fields,
+		},
+	})
+
+	logger.Warn(err.Error())
+
+	return true
+}
+
 func newResponse(c *gin.Context, statusCode int, data, response interface{}) {
 	c.AbortWithStatusJSON(statusCode, Response{
 		Response: response,
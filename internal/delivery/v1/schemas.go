@@ -0,0 +1,258 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type createSchemaInp struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema" swaggertype:"object"`
+}
+
+func (s *createSchemaInp) validate() error {
+	if !validateSchemaName(s.Name) {
+		return domain.ErrInvalidSchemaName
+	}
+
+	if len(s.Schema) == 0 {
+		return domain.ErrInvalidSchema
+	}
+
+	return nil
+}
+
+type createSchemaData struct {
+	Id string `json:"id"`
+}
+
+type updateSchemaInp struct {
+	Schema json.RawMessage `json:"schema" swaggertype:"object"`
+}
+
+func (s *updateSchemaInp) validate() error {
+	if len(s.Schema) == 0 {
+		return domain.ErrInvalidSchema
+	}
+
+	return nil
+}
+
+type getSchemasData struct {
+	Schemas []domain.Schema `json:"schemas"`
+}
+
+// @Summary Create schema
+// @Security UsersAuth
+// @Tags schemas
+// @Description Register a JSON Schema for documents of one type, documents refer to it by the name in the type field. References to other schemas by URL aren't allowed
+// @ModuleID createSchema
+// @Accept json
+// @Produce json
+// @Param input body createSchemaInp true "Schema"
+// @Success 200 {object} swagData{data=createSchemaData} "Schema created"
+// @Failure 400 {object} swagError "Bad Request, details describe why the schema is invalid"
+// @Failure 409 {object} swagError "Schema name is busy"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /schemas [post]
+func (h *Handler) createSchema(c *gin.Context) {
+	var inp createSchemaInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	schema := &domain.Schema{
+		Name:   inp.Name,
+		Schema: inp.Schema,
+	}
+
+	if err := h.service.Schema.Create(schema, getUserIdByContext(c)); err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrSchemaNameIsBusy) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, createSchemaData{
+		Id: schema.Id,
+	}, nil)
+}
+
+// @Summary Get schemas
+// @Security UsersAuth
+// @Tags schemas
+// @Description Get all registered schemas without their bodies
+// @ModuleID getSchemas
+// @Accept json
+// @Produce json
+// @Success 200 {object} swagData{data=getSchemasData} "Schemas list"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /schemas [get]
+func (h *Handler) getSchemas(c *gin.Context) {
+	schemas, err := h.service.Schema.GetAll()
+	if err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getSchemasData{
+		Schemas: schemas,
+	}, nil)
+}
+
+// @Summary Get schema by name
+// @Security UsersAuth
+// @Tags schemas
+// @Description Get schema with its body
+// @ModuleID getSchema
+// @Accept json
+// @Produce json
+// @Param name path string true "Schema name"
+// @Success 200 {object} swagData{data=domain.Schema} "Schema"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Schema not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /schemas/{name} [get]
+func (h *Handler) getSchema(c *gin.Context) {
+	name := c.Param("name")
+
+	if name == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	schema, err := h.service.Schema.GetByName(name)
+	if err != nil {
+		if errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, schema, nil)
+}
+
+// @Summary Update schema
+// @Security UsersAuth
+// @Tags schemas
+// @Description Replace the schema body. Existing documents aren't revalidated, they are checked against the new schema on their next change. Only the owner can update the schema
+// @ModuleID updateSchema
+// @Accept json
+// @Produce json
+// @Param name path string true "Schema name"
+// @Param input body updateSchemaInp true "Schema body"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request, details describe why the schema is invalid"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Schema not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /schemas/{name} [put]
+func (h *Handler) updateSchema(c *gin.Context) {
+	name := c.Param("name")
+
+	if name == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp updateSchemaInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	if err := h.service.Schema.Update(name, getUserIdByContext(c), inp.Schema); err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		name: true,
+	})
+}
+
+// @Summary Delete schema
+// @Security UsersAuth
+// @Tags schemas
+// @Description Delete schema, a schema can't be deleted while documents use it. Only the owner can delete the schema
+// @ModuleID deleteSchema
+// @Accept json
+// @Produce json
+// @Param name path string true "Schema name"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Schema not found"
+// @Failure 409 {object} swagError "Schema is used by documents"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /schemas/{name} [delete]
+func (h *Handler) deleteSchema(c *gin.Context) {
+	name := c.Param("name")
+
+	if name == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.Schema.Delete(name, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrSchemaIsUsed) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		name: true,
+	})
+}
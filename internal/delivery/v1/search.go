@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type searchDocumentsData struct {
+	Results []domain.SearchResult `json:"results"`
+}
+
+// @Summary Search documents
+// @Security UsersAuth
+// @Tags docs
+// @Description Full-text search by document names, JSON data and text of uploaded files among documents available to the user, the best matches first. Supports quoted phrases, OR and -word
+// @ModuleID searchDocuments
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Limit for pagination"
+// @Param page query int false "Page for pagination"
+// @Success 200 {object} swagData{data=searchDocumentsData} "Search results"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/search [get]
+func (h *Handler) searchDocuments(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+
+	if query == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrQueryIsEmpty.Error(), domain.ErrQueryIsEmpty.Error())
+
+		return
+	}
+
+	params := domain.PrepareFillterParams("", "", c.Query("limit"), c.Query("page"))
+
+	results, err := h.service.Document.Search(query, getUserIdByContext(c), params)
+	if err != nil {
+		errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, searchDocumentsData{
+		Results: results,
+	}, nil)
+}
@@ -0,0 +1,355 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type createUploadInp struct {
+	FileName string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+func (u *createUploadInp) validate() error {
+	if u.FileName == "" {
+		return domain.ErrNameIsEmpty
+	}
+
+	if u.Size <= 0 {
+		return domain.ErrInvalidUploadSize
+	}
+
+	return nil
+}
+
+type writeUploadChunkData struct {
+	Offset int64 `json:"offset"`
+}
+
+type completeUploadInp struct {
+	Name         string   `json:"name"`
+	IsPublic     bool     `json:"public"`
+	Mime         string   `json:"mime"`
+	Grants       []string `json:"grant"`
+	DocumentData string   `json:"json"`
+	Type         string   `json:"type"`
+	FolderId     string   `json:"folder"`
+}
+
+func (u *completeUploadInp) validate() error {
+	if u.Name == "" {
+		return domain.ErrNameIsEmpty
+	}
+
+	if u.DocumentData != "" && !json.Valid([]byte(u.DocumentData)) {
+		return domain.ErrInvalidJSON
+	}
+
+	return nil
+}
+
+// setUploadHeaders - progress of the upload in the headers, clients resume the upload from Upload-Offset
+func setUploadHeaders(c *gin.Context, upload *domain.Upload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	c.Header("Cache-Control", "no-store")
+}
+
+// @Summary Create upload
+// @Security UsersAuth
+// @Tags uploads
+// @Description Start a resumable upload of a large file. The file is sent in chunks with PATCH /uploads/{id} and becomes a document with POST /uploads/{id}/complete. Uploads not changed for the TTL are deleted
+// @ModuleID createUpload
+// @Accept json
+// @Produce json
+// @Param input body createUploadInp true "File name and size in bytes"
+// @Success 200 {object} swagData{data=domain.Upload} "Upload created"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 413 {object} swagError "File is too large"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /uploads [post]
+func (h *Handler) createUpload(c *gin.Context) {
+	var inp createUploadInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	upload := &domain.Upload{
+		FileName: inp.FileName,
+		Size:     inp.Size,
+	}
+
+	if err := h.service.Upload.Create(upload, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrInvalidUploadSize) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrFileIsTooLarge) {
+			errResponse(c, http.StatusRequestEntityTooLarge, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	newResponse(c, http.StatusOK, upload, nil)
+}
+
+// @Summary Get upload
+// @Security UsersAuth
+// @Tags uploads
+// @Description Get the progress of the upload, the next chunk starts at the offset. Own uploads only
+// @ModuleID getUpload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} swagData{data=domain.Upload} "Upload, the offset is also in the Upload-Offset header"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Upload not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /uploads/{id} [get]
+func (h *Handler) getUpload(c *gin.Context) {
+	uploadId := c.Param("id")
+
+	if uploadId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	upload, err := h.service.Upload.GetById(uploadId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrUploadNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	newResponse(c, http.StatusOK, upload, nil)
+}
+
+// @Summary Write upload chunk
+// @Security UsersAuth
+// @Tags uploads
+// @Description Send the next chunk of the file, the body is the chunk. The chunk must start at the offset of the upload, after a failed request the client gets the offset with GET /uploads/{id} and sends the rest from it. Content-Length is required
+// @ModuleID writeUploadChunk
+// @Accept application/offset+octet-stream
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param Upload-Offset header int true "Offset of the chunk in the file"
+// @Success 200 {object} swagData{data=writeUploadChunkData} "Chunk saved, the new offset is also in the Upload-Offset header"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Upload not found"
+// @Failure 409 {object} swagError "Offset doesn't match the upload, the current one is in the Upload-Offset header"
+// @Failure 411 {object} swagError "Content-Length is required"
+// @Failure 413 {object} swagError "Chunk is too large"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /uploads/{id} [patch]
+func (h *Handler) writeUploadChunk(c *gin.Context) {
+	uploadId := c.Param("id")
+
+	if uploadId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		errResponse(c, http.StatusBadRequest, domain.ErrInvalidUploadOffset.Error(), domain.ErrInvalidUploadOffset.Error())
+
+		return
+	}
+
+	if c.Request.ContentLength <= 0 {
+		errResponse(c, http.StatusLengthRequired, domain.ErrInvalidUploadSize.Error(), domain.ErrInvalidUploadSize.Error())
+
+		return
+	}
+
+	userId := getUserIdByContext(c)
+
+	newOffset, err := h.service.Upload.WriteChunk(uploadId, userId, offset, c.Request.Body, c.Request.ContentLength)
+	if err != nil {
+		if errors.Is(err, domain.ErrUploadNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrUploadOffsetMismatch) {
+			if upload, err := h.service.Upload.GetById(uploadId, userId); err == nil {
+				setUploadHeaders(c, upload)
+			}
+
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrChunkIsTooLarge) {
+			errResponse(c, http.StatusRequestEntityTooLarge, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidUploadSize) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	newResponse(c, http.StatusOK, writeUploadChunkData{
+		Offset: newOffset,
+	}, nil)
+}
+
+// @Summary Complete upload
+// @Security UsersAuth
+// @Tags uploads
+// @Description Create a document with the uploaded file, the upload is deleted then. All chunks must be sent
+// @ModuleID completeUpload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param input body completeUploadInp true "Document metadata, grant is an array of login or @group with an optional :permission (read, write, share)"
+// @Success 200 {object} swagData{data=uploadDocumentData} "Document uploaded successfully"
+// @Failure 400 {object} swagError "Bad Request, details list fields that don't match the schema"
+// @Failure 404 {object} swagError "Upload, folder or schema not found"
+// @Failure 409 {object} swagError "Upload is incomplete or being completed"
+// @Failure 415 {object} swagError "File type is not allowed"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /uploads/{id}/complete [post]
+func (h *Handler) completeUpload(c *gin.Context) {
+	uploadId := c.Param("id")
+
+	if uploadId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	var inp completeUploadInp
+	if err := c.BindJSON(&inp); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), domain.ErrCantParseJSON.Error())
+
+		return
+	}
+
+	if err := inp.validate(); err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	grants, err := parseGrants(inp.Grants)
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	userId := getUserIdByContext(c)
+
+	upload, err := h.service.Upload.GetById(uploadId, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrUploadNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	document := &domain.Document{
+		Name:         inp.Name,
+		Mime:         inp.Mime,
+		IsPublic:     inp.IsPublic,
+		DocumentData: inp.DocumentData,
+		Type:         inp.Type,
+		Grants:       grants,
+	}
+
+	if inp.FolderId != "" {
+		document.FolderId = &inp.FolderId
+	}
+
+	unknownGrants, err := h.service.Upload.Complete(uploadId, userId, document)
+	if err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrUploadNotFound) || errors.Is(err, domain.ErrFolderNotFound) ||
+			errors.Is(err, domain.ErrSchemaNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrUploadIsIncomplete) || errors.Is(err, domain.ErrUploadIsCompleting) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrInvalidJSON) {
+			errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrMimeNotAllowed) {
+			errResponse(c, http.StatusUnsupportedMediaType, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, uploadDocumentData{
+		Id:            document.Id,
+		DocumentData:  inp.DocumentData,
+		File:          upload.FileName,
+		UnknownGrants: unknownGrants,
+	}, nil)
+}
+
+// @Summary Delete upload
+// @Security UsersAuth
+// @Tags uploads
+// @Description Cancel the upload and delete the sent chunks
+// @ModuleID deleteUpload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Upload not found"
+// @Failure 409 {object} swagError "Upload is being completed"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /uploads/{id} [delete]
+func (h *Handler) deleteUpload(c *gin.Context) {
+	uploadId := c.Param("id")
+
+	if uploadId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	if err := h.service.Upload.Delete(uploadId, getUserIdByContext(c)); err != nil {
+		if errors.Is(err, domain.ErrUploadNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrUploadIsCompleting) {
+			errResponse(c, http.StatusConflict, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		uploadId: true,
+	})
+}
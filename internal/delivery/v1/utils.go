@@ -1,21 +1,97 @@
 package v1
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
 )
 
-func (h *Handler) filePathGenerator(userId, fileName string) string {
-	return fmt.Sprintf("%v/%v/%v", h.config.Documents.UploadsDir, userId, fileName)
-}
+const (
+	defaultContentType = "application/octet-stream"
+	jsonContentType    = "application/json; charset=utf-8"
+)
 
 func getUserIdByContext(c *gin.Context) string {
 	return c.MustGet("userId").(string)
 }
 
-func fileExists(filePath string) bool {
-	_, err := os.Stat(filePath)
-	return !os.IsNotExist(err)
+// getSessionIdByContext - session of the access token, empty for tokens issued before sessions
+func getSessionIdByContext(c *gin.Context) string {
+	return c.GetString("sessionId")
+}
+
+// getClientByContext - client the request is made from, stored with the session
+func getClientByContext(c *gin.Context) domain.Client {
+	return domain.Client{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+func fileContentType(mime string) string {
+	if mime == "" {
+		return defaultContentType
+	}
+
+	return mime
+}
+
+// cleanLogins - removes empty and duplicate logins
+func cleanLogins(logins []string) []string {
+	cleaned := make([]string, 0, len(logins))
+	seen := make(map[string]struct{}, len(logins))
+	for _, login := range logins {
+		if _, ok := seen[login]; ok || login == "" {
+			continue
+		}
+
+		seen[login] = struct{}{}
+		cleaned = append(cleaned, login)
+	}
+
+	return cleaned
+}
+
+// parseGrants - parses grants in "subject[:permission]" form, the subject is a login or "@group".
+// Skips empty subjects, if a subject is repeated, the last permission wins
+func parseGrants(grants []string) ([]domain.Grant, error) {
+	parsed := make([]domain.Grant, 0, len(grants))
+	index := make(map[string]int, len(grants))
+	for _, g := range grants {
+		grant, err := domain.ParseGrant(g)
+		if err != nil {
+			return nil, err
+		}
+
+		subject := grant.Subject()
+		if subject == "" {
+			continue
+		}
+
+		if i, ok := index[subject]; ok {
+			parsed[i] = grant
+			continue
+		}
+
+		index[subject] = len(parsed)
+		parsed = append(parsed, grant)
+	}
+
+	return parsed, nil
+}
+
+// excludeLogins - returns logins that aren't in the excluded list
+func excludeLogins(logins, excluded []string) []string {
+	skip := make(map[string]struct{}, len(excluded))
+	for _, login := range excluded {
+		skip[login] = struct{}{}
+	}
+
+	result := make([]string, 0, len(logins))
+	for _, login := range logins {
+		if _, ok := skip[login]; !ok {
+			result = append(result, login)
+		}
+	}
+
+	return result
 }
@@ -15,6 +15,14 @@ func validateLogin(login string) bool {
 	return regexp.MustCompile(`^[a-zA-Z0-9]*$`).MatchString(login)
 }
 
+func validateGroupName(name string) bool {
+	if len(name) == 0 || len(name) > 64 {
+		return false
+	}
+
+	return regexp.MustCompile(`^[a-zA-Z0-9_-]*$`).MatchString(name)
+}
+
 func validatePassword(password string) bool {
 	hasUpper := false
 	hasLower := false
@@ -39,3 +47,11 @@ func validatePassword(password string) bool {
 
 	return hasUpper && hasLower && hasDigit && hasSymbol && len(password) > 8
 }
+
+func validateSchemaName(name string) bool {
+	if len(name) == 0 || len(name) > 64 {
+		return false
+	}
+
+	return regexp.MustCompile(`^[a-zA-Z0-9_.-]*$`).MatchString(name)
+}
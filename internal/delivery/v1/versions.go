@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sixojke/test-astral/domain"
+)
+
+type getDocumentVersionsData struct {
+	Versions *[]domain.DocumentVersion `json:"versions"`
+}
+
+// @Summary Get document versions
+// @Security UsersAuth
+// @Tags versions
+// @Description Get content revisions of the document, newest first
+// @ModuleID getDocumentVersions
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} swagData{data=getDocumentVersionsData} "Versions list"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Document not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/versions [get]
+func (h *Handler) getDocumentVersions(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	versions, err := h.service.Document.GetVersions(documentId, getUserIdByContext(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrDocumentNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, getDocumentVersionsData{
+		Versions: versions,
+	}, nil)
+}
+
+// @Summary Get document version
+// @Security UsersAuth
+// @Tags versions
+// @Description Get content of the document version. Returns JSON data or the file
+// @ModuleID getDocumentVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param n path int true "Version number"
+// @Success 200 {object} swagData{data=domain.DocumentVersion} "Version"
+// @Failure 400 {object} swagError "Bad Request"
+// @Failure 404 {object} swagError "Version not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/versions/{n} [get]
+func (h *Handler) getDocumentVersion(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	version, err := parseVersion(c)
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	documentVersion, err := h.service.Document.GetVersion(documentId, getUserIdByContext(c), version)
+	if err != nil {
+		if errors.Is(err, domain.ErrVersionNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	if !documentVersion.IsFile {
+		newResponse(c, http.StatusOK, documentVersion, nil)
+
+		return
+	}
+
+	file, info, err := h.service.Document.OpenFile(documentVersion.FilePath)
+	if err != nil {
+		if errors.Is(err, domain.ErrFileIsDamagedOrNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, fileContentType(documentVersion.Mime), file, nil)
+}
+
+// @Summary Restore document version
+// @Security UsersAuth
+// @Tags versions
+// @Description Make the version content current, the replaced content is kept as a new version. Requires the write permission
+// @ModuleID restoreDocumentVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param n path int true "Version number"
+// @Success 200 {object} swagResponse{response=map[string]bool} "Success"
+// @Failure 400 {object} swagError "Bad Request, details list fields that don't match the schema of the document"
+// @Failure 403 {object} swagError "Permission denied"
+// @Failure 404 {object} swagError "Version not found"
+// @Failure 500 {object} swagError "Internal Server Error"
+// @Router /docs/{id}/versions/{n}/restore [post]
+func (h *Handler) restoreDocumentVersion(c *gin.Context) {
+	documentId := c.Param("id")
+
+	if documentId == "" {
+		errResponse(c, http.StatusBadRequest, domain.ErrParameterIsEmpty.Error(), domain.ErrParameterIsEmpty.Error())
+
+		return
+	}
+
+	version, err := parseVersion(c)
+	if err != nil {
+		errResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+
+		return
+	}
+
+	if err := h.service.Document.RestoreVersion(documentId, getUserIdByContext(c), version); err != nil {
+		if validationErrResponse(c, err) {
+			return
+		}
+
+		if errors.Is(err, domain.ErrDocumentNotFound) || errors.Is(err, domain.ErrVersionNotFound) {
+			errResponse(c, http.StatusNotFound, err.Error(), err.Error())
+		} else if errors.Is(err, domain.ErrPermissionDenied) {
+			errResponse(c, http.StatusForbidden, err.Error(), err.Error())
+		} else {
+			errResponse(c, http.StatusInternalServerError, err.Error(), domain.ErrInternalServerError.Error())
+		}
+
+		return
+	}
+
+	newResponse(c, http.StatusOK, nil, map[string]bool{
+		documentId: true,
+	})
+}
+
+func parseVersion(c *gin.Context) (int, error) {
+	version, err := strconv.Atoi(c.Param("n"))
+	if err != nil || version <= 0 {
+		return 0, domain.ErrInvalidVersion
+	}
+
+	return version, nil
+}
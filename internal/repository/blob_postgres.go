@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// BlobPostgres - reference counts of stored files. Files are shared by documents with identical content,
+// a file is deleted from the storage only while its row is locked and nothing references it
+type BlobPostgres struct {
+	db *sqlx.DB
+}
+
+func NewBlobPostgres(db *sqlx.DB) *BlobPostgres {
+	return &BlobPostgres{
+		db: db,
+	}
+}
+
+// Acquire - adds a reference to the blob, a request holds it while it stores the file and saves the document
+func (r *BlobPostgres) Acquire(key string) error {
+	logger.Debugf("acquire blob: params=[key=%v]", key)
+
+	if err := acquireBlob(r.db, key); err != nil {
+		logger.Errorf("failed to acquire blob: key=%v: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+// Release - removes a reference of the blob, returns true if the blob is no longer referenced
+func (r *BlobPostgres) Release(key string) (orphaned bool, err error) {
+	logger.Debugf("release blob: params=[key=%v]", key)
+
+	orphaned, err = releaseBlob(r.db, key)
+	if err != nil {
+		logger.Errorf("failed to release blob: key=%v: %v", key, err)
+		return false, err
+	}
+
+	return orphaned, nil
+}
+
+// Delete - deletes the unreferenced blob with deleteFile. The row of the blob stays locked until the file
+// is deleted, a concurrent Acquire waits and stores the file again. Returns false if the blob is referenced
+func (r *BlobPostgres) Delete(key string, deleteFile func(key string) error) (deleted bool, err error) {
+	logger.Debugf("delete blob: params=[key=%v]", key)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	// A file without the row is locked by a placeholder row
+	query := `
+		INSERT INTO blobs (
+			key,
+			ref_count
+		) VALUES (
+			$1, 0
+		)
+		ON CONFLICT (key) DO NOTHING
+	`
+
+	if _, err := tx.Exec(query, key); err != nil {
+		logger.Errorf("failed to insert blob: %v", err)
+		return false, err
+	}
+
+	query = `
+		SELECT
+			b.ref_count > 0
+			OR EXISTS (SELECT 1 FROM documents d WHERE d.file_path = b.key)
+			OR EXISTS (SELECT 1 FROM document_versions v WHERE v.file_path = b.key)
+			OR EXISTS (SELECT 1 FROM upload_chunks c WHERE c.blob_key = b.key)
+		FROM blobs b
+		WHERE b.key = $1
+		FOR UPDATE OF b
+	`
+
+	var referenced bool
+	if err := tx.Get(&referenced, query, key); err != nil {
+		logger.Errorf("failed to lock blob: %v", err)
+		return false, err
+	}
+
+	if referenced {
+		return false, nil
+	}
+
+	if err := deleteFile(key); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM blobs WHERE key = $1`, key); err != nil {
+		logger.Errorf("failed to delete blob: %v", err)
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// StripKeyPrefix - turns file paths saved before the blob storage into keys relative to the storage root.
+// Such paths start with the uploads directory of the time, migration 000006 strips only the default one.
+// Returns the number of converted documents
+func (r *BlobPostgres) StripKeyPrefix(prefix string) (documents int64, err error) {
+	logger.Debugf("strip key prefix: params=[prefix=%v]", prefix)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	query := `
+		UPDATE documents
+		SET
+			file_path = SUBSTRING(file_path FROM LENGTH($1) + 1),
+			content_path = CASE
+				WHEN LEFT(content_path, LENGTH($1)) = $1 THEN SUBSTRING(content_path FROM LENGTH($1) + 1)
+				ELSE content_path
+			END
+		WHERE LEFT(file_path, LENGTH($1)) = $1
+	`
+
+	result, err := tx.Exec(query, prefix)
+	if err != nil {
+		logger.Errorf("failed to strip document file paths: %v", err)
+		return 0, err
+	}
+
+	if documents, err = result.RowsAffected(); err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE document_versions
+		SET file_path = SUBSTRING(file_path FROM LENGTH($1) + 1)
+		WHERE LEFT(file_path, LENGTH($1)) = $1
+	`
+
+	if _, err := tx.Exec(query, prefix); err != nil {
+		logger.Errorf("failed to strip version file paths: %v", err)
+		return 0, err
+	}
+
+	query = `
+		UPDATE blobs
+		SET key = SUBSTRING(key FROM LENGTH($1) + 1)
+		WHERE LEFT(key, LENGTH($1)) = $1
+	`
+
+	if _, err := tx.Exec(query, prefix); err != nil {
+		logger.Errorf("failed to strip blob keys: %v", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return documents, nil
+}
+
+// acquireBlob - increments the reference count of the blob
+func acquireBlob(e sqlx.Execer, key string) error {
+	query := `
+		INSERT INTO blobs (
+			key,
+			ref_count
+		) VALUES (
+			$1, 1
+		)
+		ON CONFLICT (key) DO UPDATE
+		SET ref_count = blobs.ref_count + 1
+	`
+
+	_, err := e.Exec(query, key)
+
+	return err
+}
+
+// releaseBlob - decrements the reference count of the blob, returns true if the blob is no longer referenced.
+// The row is kept with zero references, the file is deleted with BlobPostgres.Delete under the row lock
+func releaseBlob(q sqlx.Queryer, key string) (bool, error) {
+	query := `
+		UPDATE blobs
+		SET ref_count = ref_count - 1
+		WHERE key = $1
+		RETURNING ref_count
+	`
+
+	var refCount int
+	if err := sqlx.Get(q, &refCount, query, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return refCount <= 0, nil
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/cache"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// DocumentCache - caching decorator for the Document repository.
+//
+// Document entries are keyed by document id, listings by the owner of the
+// listed documents, so a change of a document invalidates every user who can see it.
+// Changes made by users with grants don't know the owner and invalidate all listings.
+// Loaded values are cached only if nothing was invalidated during the load, otherwise
+// a read racing a change could cache the state from before it.
+type DocumentCache struct {
+	Document
+	cache *cache.Cache
+}
+
+func NewDocumentCache(repo Document, cache *cache.Cache) *DocumentCache {
+	return &DocumentCache{
+		Document: repo,
+		cache:    cache,
+	}
+}
+
+func documentKey(documentId, userId string) string {
+	return fmt.Sprintf("doc:%v:%v", documentId, userId)
+}
+
+func documentPrefix(documentId string) string {
+	return fmt.Sprintf("doc:%v:", documentId)
+}
+
+func listKey(ownerId, userId string, params *domain.FilterParams) string {
+	return fmt.Sprintf("list:%v:%v:%+v", ownerId, userId, *params)
+}
+
+func listPrefix(ownerId string) string {
+	return fmt.Sprintf("list:%v:", ownerId)
+}
+
+func (r *DocumentCache) Create(document *domain.Document, userId string) (unknownGrants []string, err error) {
+	unknownGrants, err = r.Document.Create(document, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.DeletePrefix(listPrefix(userId))
+
+	return unknownGrants, nil
+}
+
+func (r *DocumentCache) GetCurrentUserDocuments(currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
+	key := listKey(currentUserId, currentUserId, params)
+	if list, ok := r.cache.Get(key); ok {
+		return copyDocumentList(list.(*domain.DocumentList)), nil
+	}
+
+	generation := r.cache.Generation()
+	list, err := r.Document.GetCurrentUserDocuments(currentUserId, params)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.SetIfGeneration(key, copyDocumentList(list), generation)
+
+	return list, nil
+}
+
+func (r *DocumentCache) GetOtherUserDocuments(userId string, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
+	key := listKey(userId, currentUserId, params)
+	if list, ok := r.cache.Get(key); ok {
+		return copyDocumentList(list.(*domain.DocumentList)), nil
+	}
+
+	generation := r.cache.Generation()
+	list, err := r.Document.GetOtherUserDocuments(userId, currentUserId, params)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.SetIfGeneration(key, copyDocumentList(list), generation)
+
+	return list, nil
+}
+
+func (r *DocumentCache) GetById(documentId, userId string) (*domain.Document, error) {
+	key := documentKey(documentId, userId)
+	if document, ok := r.cache.Get(key); ok {
+		return copyDocument(document.(*domain.Document)), nil
+	}
+
+	generation := r.cache.Generation()
+	document, err := r.Document.GetById(documentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.SetIfGeneration(key, copyDocument(document), generation)
+
+	return document, nil
+}
+
+func (r *DocumentCache) Update(documentId, userId string, update *domain.DocumentUpdate,
+	validate func(schema, data string) error) (unknownGrants []string, err error) {
+	unknownGrants, err = r.Document.Update(documentId, userId, update, validate)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(documentId)
+
+	return unknownGrants, nil
+}
+
+func (r *DocumentCache) UpdateData(documentId, userId string,
+	update func(document *domain.Document) (data string, err error)) (newVersion int, err error) {
+	newVersion, err = r.Document.UpdateData(documentId, userId, update)
+	if err != nil {
+		return 0, err
+	}
+
+	r.invalidate(documentId)
+
+	return newVersion, nil
+}
+
+func (r *DocumentCache) UpdateFile(documentId, userId, filePath, mime string, mimeMismatch bool) (oldFilePath string, err error) {
+	oldFilePath, err = r.Document.UpdateFile(documentId, userId, filePath, mime, mimeMismatch)
+	if err != nil {
+		return "", err
+	}
+
+	r.invalidate(documentId)
+
+	return oldFilePath, nil
+}
+
+func (r *DocumentCache) Delete(documentId, userId string) (filePaths []string, err error) {
+	filePaths, err = r.Document.Delete(documentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateOwned(documentId, userId)
+
+	return filePaths, nil
+}
+
+func (r *DocumentCache) RestoreVersion(documentId, userId string, version int) error {
+	if err := r.Document.RestoreVersion(documentId, userId, version); err != nil {
+		return err
+	}
+
+	r.invalidate(documentId)
+
+	return nil
+}
+
+func (r *DocumentCache) AddGrants(documentId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	unknown, err = r.Document.AddGrants(documentId, userId, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(documentId)
+
+	return unknown, nil
+}
+
+func (r *DocumentCache) DeleteGrants(documentId, userId string, subjects []string) (unknown []string, err error) {
+	unknown, err = r.Document.DeleteGrants(documentId, userId, subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(documentId)
+
+	return unknown, nil
+}
+
+// invalidate - drops the cached document and all listings
+func (r *DocumentCache) invalidate(documentId string) {
+	logger.Debugf("invalidate document cache: params=[documentId=%v]", documentId)
+
+	r.cache.DeletePrefix(documentPrefix(documentId))
+	r.cache.DeletePrefix("list:")
+}
+
+// invalidateOwned - drops the cached document and all listings of its owner
+func (r *DocumentCache) invalidateOwned(documentId, ownerId string) {
+	logger.Debugf("invalidate document cache: params=[documentId=%v ownerId=%v]", documentId, ownerId)
+
+	r.cache.DeletePrefix(documentPrefix(documentId))
+	r.cache.DeletePrefix(listPrefix(ownerId))
+}
+
+func copyDocument(document *domain.Document) *domain.Document {
+	doc := *document
+	doc.Grants = append([]domain.Grant(nil), document.Grants...)
+
+	return &doc
+}
+
+func copyDocumentList(list *domain.DocumentList) *domain.DocumentList {
+	l := *list
+
+	docs := make([]domain.Document, 0, len(*list.Documents))
+	for i := range *list.Documents {
+		docs = append(docs, *copyDocument(&(*list.Documents)[i]))
+	}
+	l.Documents = &docs
+
+	if list.Total != nil {
+		total := *list.Total
+		l.Total = &total
+	}
+
+	return &l
+}
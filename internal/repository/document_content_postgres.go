@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// contentPendingCondition - files whose text isn't extracted yet, content_path keeps
+// the file path the text was extracted from
+const contentPendingCondition = `d.is_file AND d.file_path != '' AND d.content_path IS DISTINCT FROM d.file_path`
+
+type ContentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewContentPostgres(db *sqlx.DB) *ContentPostgres {
+	return &ContentPostgres{
+		db: db,
+	}
+}
+
+// GetPending - returns ids of documents with files waiting for the text extraction, the oldest first
+func (r *ContentPostgres) GetPending(limit int) ([]string, error) {
+	logger.Debugf("get pending contents: params=[limit=%v]", limit)
+
+	query := `
+		SELECT d.id
+		FROM documents d
+		WHERE ` + contentPendingCondition + `
+		ORDER BY d.updated_at
+		LIMIT $1
+	`
+
+	ids := make([]string, 0)
+	if err := r.db.Select(&ids, query, limit); err != nil {
+		logger.Errorf("failed to get pending contents: %v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetById - returns the file of the document if its text isn't extracted yet
+func (r *ContentPostgres) GetById(documentId string) (*domain.DocumentContent, error) {
+	logger.Debugf("get pending content: params=[documentId=%v]", documentId)
+
+	query := `
+		SELECT
+			d.id,
+			d.name,
+			d.mime,
+			d.file_path
+		FROM documents d
+		WHERE
+			d.id = $1
+			AND ` + contentPendingCondition + `
+	`
+
+	var content domain.DocumentContent
+	if err := r.db.Get(&content, query, documentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDocumentNotFound
+		}
+
+		logger.Errorf("failed to get pending content: %v", err)
+		return nil, err
+	}
+
+	return &content, nil
+}
+
+// Set - saves the text extracted from the file, nil text means the file has no text.
+// Nothing is saved if the file of the document has been replaced in the meantime
+func (r *ContentPostgres) Set(documentId, filePath string, text *string) error {
+	logger.Debugf("set content: params=[documentId=%v filePath=%v]", documentId, filePath)
+
+	query := `
+		UPDATE documents
+		SET
+			content_text = $1,
+			content_path = file_path
+		WHERE
+			id = $2
+			AND file_path = $3
+	`
+
+	if _, err := r.db.Exec(query, text, documentId, filePath); err != nil {
+		logger.Errorf("failed to set content: documentId=%v: %v", documentId, err)
+		return err
+	}
+
+	return nil
+}
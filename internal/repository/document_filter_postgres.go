@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+)
+
+// documentColumns - columns of documents d for domain.DocumentFields
+var documentColumns = map[string]string{
+	"name":          "d.name",
+	"mime":          "d.mime",
+	"mime_mismatch": "d.mime_mismatch",
+	"is_file":       "d.is_file",
+	"is_public":     "d.is_public",
+	"version":       "d.version",
+	"created_at":    "d.created_at",
+	"updated_at":    "d.updated_at",
+}
+
+var sqlOperators = map[domain.FilterOperator]string{
+	domain.OperatorEq:   "=",
+	domain.OperatorNe:   "!=",
+	domain.OperatorLike: "LIKE",
+	domain.OperatorGt:   ">",
+	domain.OperatorLt:   "<",
+}
+
+// documentData - JSON data of documents d
+const documentData = "d.document_data"
+
+// filterConditions - returns conditions of the filters joined with AND and appends their values to args
+func filterConditions(filters []domain.Filter, args []interface{}) (string, []interface{}) {
+	var conditions strings.Builder
+	for _, filter := range filters {
+		var condition string
+		condition, args = filterCondition(filter, args)
+
+		conditions.WriteString(" AND ")
+		conditions.WriteString(condition)
+	}
+
+	return conditions.String(), args
+}
+
+func filterCondition(filter domain.Filter, args []interface{}) (string, []interface{}) {
+	placeholder := func(arg interface{}) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Kind != domain.FieldJSON {
+		column := documentColumns[filter.Field]
+		if filter.Operator == domain.OperatorIn {
+			return column + " = ANY(" + placeholder(pq.Array(filter.Values)) + ")", args
+		}
+
+		return column + " " + sqlOperators[filter.Operator] + " " + placeholder(filter.Values[0]), args
+	}
+
+	path := placeholder(pq.Array(filter.Path)) + "::TEXT[]"
+
+	switch filter.Operator {
+	case domain.OperatorLike:
+		return "(" + documentData + " #>> " + path + ") LIKE " + placeholder(filter.Values[0]), args
+	case domain.OperatorIn:
+		values := make([]string, 0, len(filter.Values))
+		for _, v := range filter.Values {
+			values = append(values, jsonValue(v))
+		}
+
+		return "(" + documentData + " #> " + path + ") = ANY(" + placeholder(pq.Array(values)) + "::JSONB[])", args
+	default:
+		return "(" + documentData + " #> " + path + ") " + sqlOperators[filter.Operator] + " " +
+			placeholder(jsonValue(filter.Values[0])) + "::JSONB", args
+	}
+}
+
+// jsonValue - values of JSON filters are JSON literals: 10, true, "10".
+// Anything else is taken as a string, so name:eq:report doesn't need quotes
+func jsonValue(value string) string {
+	if json.Valid([]byte(value)) {
+		return value
+	}
+
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// Default orders of listings, the sort of the request replaces them
+var (
+	defaultListSort   = []domain.Sort{{Field: "created_at"}}
+	defaultFolderSort = []domain.Sort{{Field: "name"}}
+)
+
+// listDocuments - returns a page of documents d matching the condition, args are values of the condition.
+// One more document than the limit is fetched to know if there is a next page
+func (r *DocumentPostgres) listDocuments(where string, args []interface{}, params *domain.FilterParams,
+	defaultSort []domain.Sort) (*domain.DocumentList, error) {
+	conditions, args := filterConditions(params.Filters, args)
+	where += conditions
+
+	sort := params.Sort
+	if len(sort) == 0 {
+		sort = defaultSort
+	}
+
+	list := &domain.DocumentList{}
+
+	if params.Total {
+		var total int
+		if err := r.db.Get(&total, `SELECT COUNT(*) FROM documents d WHERE `+where, args...); err != nil {
+			return nil, err
+		}
+
+		list.Total = &total
+	}
+
+	if params.Cursor.Id != "" {
+		var keyset string
+		var err error
+		if keyset, args, err = keysetCondition(sort, params.Cursor, args); err != nil {
+			return nil, err
+		}
+
+		where += keyset
+	}
+
+	query := `
+	  SELECT 
+		d.id,
+		d.name,
+		d.mime,
+		d.mime_mismatch,
+		d.file_path,
+		d.is_file,
+		d.is_public,
+		d.version,
+		d.folder_id,
+		COALESCE(STRING_AGG(` + grantSubject + ` || ':' || g.permission, ','), '') AS grants,
+		d.created_at,
+		d.updated_at
+	  FROM documents d
+	  LEFT JOIN document_grants g ON d.id = g.document_id
+	  WHERE ` + where + `
+	  GROUP BY d.id
+	  ORDER BY ` + orderBy(sort) + `
+	  LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2) + `;
+	`
+
+	args = append(args, params.Limit+1, params.Offset)
+
+	var docsDirty docsByUserIdHelp
+	if err := r.db.Select(&docsDirty, query, args...); err != nil {
+		return nil, err
+	}
+
+	if len(docsDirty) > params.Limit {
+		docsDirty = docsDirty[:params.Limit]
+		list.HasMore = true
+	}
+
+	list.Documents = docsDirty.prepare()
+
+	if list.HasMore {
+		docs := *list.Documents
+		list.NextCursor = domain.NewCursor(params.Sort, sort, &docs[len(docs)-1])
+	}
+
+	return list, nil
+}
+
+// keysetCondition - returns the condition of documents after the cursor in the sort order
+// and appends cursor values to args: (a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND d.id > $3)
+func keysetCondition(sort []domain.Sort, cursor domain.Cursor, args []interface{}) (string, []interface{}, error) {
+	if len(cursor.Values) != len(sort) {
+		return "", nil, domain.ErrInvalidCursor
+	}
+
+	var equal strings.Builder
+	alternatives := make([]string, 0, len(sort)+1)
+	for i, s := range sort {
+		if !domain.DocumentFields[s.Field].Valid(cursor.Values[i]) {
+			return "", nil, domain.ErrInvalidCursor
+		}
+
+		args = append(args, cursor.Values[i])
+		placeholder := fmt.Sprintf("$%d", len(args))
+
+		operator := ">"
+		if s.Desc {
+			operator = "<"
+		}
+
+		column := documentColumns[s.Field]
+		alternatives = append(alternatives, "("+equal.String()+column+" "+operator+" "+placeholder+")")
+		equal.WriteString(column + " = " + placeholder + " AND ")
+	}
+
+	args = append(args, cursor.Id)
+	alternatives = append(alternatives, fmt.Sprintf("(%vd.id > $%d)", equal.String(), len(args)))
+
+	return " AND (" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// orderBy - returns the ORDER BY list of the sort.
+// The id is always the last, so pages of equal values don't overlap
+func orderBy(sort []domain.Sort) string {
+	columns := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		if s.Desc {
+			columns = append(columns, documentColumns[s.Field]+" DESC")
+		} else {
+			columns = append(columns, documentColumns[s.Field]+" ASC")
+		}
+	}
+
+	return strings.Join(append(columns, "d.id"), ", ")
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+)
+
+func TestFilterCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    domain.Filter
+		args      []interface{} // args of the conditions before the filter
+		condition string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "column",
+			filter:    domain.Filter{Field: "name", Kind: domain.FieldString, Operator: domain.OperatorLike, Values: []string{"a%"}},
+			condition: "d.name LIKE $1",
+			wantArgs:  []interface{}{"a%"},
+		},
+		{
+			name:      "placeholder after other args",
+			filter:    domain.Filter{Field: "version", Kind: domain.FieldInt, Operator: domain.OperatorGt, Values: []string{"2"}},
+			args:      []interface{}{"user"},
+			condition: "d.version > $2",
+			wantArgs:  []interface{}{"user", "2"},
+		},
+		{
+			name:      "column in",
+			filter:    domain.Filter{Field: "version", Kind: domain.FieldInt, Operator: domain.OperatorIn, Values: []string{"1", "2"}},
+			condition: "d.version = ANY($1)",
+			wantArgs:  []interface{}{pq.Array([]string{"1", "2"})},
+		},
+		{
+			name: "JSON eq",
+			filter: domain.Filter{Field: "json.author.name", Kind: domain.FieldJSON, Path: []string{"author", "name"},
+				Operator: domain.OperatorEq, Values: []string{"Ivan"}},
+			condition: "(d.document_data #> $1::TEXT[]) = $2::JSONB",
+			wantArgs:  []interface{}{pq.Array([]string{"author", "name"}), `"Ivan"`},
+		},
+		{
+			name: "JSON lt with a number",
+			filter: domain.Filter{Field: "json.total", Kind: domain.FieldJSON, Path: []string{"total"},
+				Operator: domain.OperatorLt, Values: []string{"10"}},
+			args:      []interface{}{"user"},
+			condition: "(d.document_data #> $2::TEXT[]) < $3::JSONB",
+			wantArgs:  []interface{}{"user", pq.Array([]string{"total"}), "10"},
+		},
+		{
+			name: "JSON like compares text",
+			filter: domain.Filter{Field: "json.title", Kind: domain.FieldJSON, Path: []string{"title"},
+				Operator: domain.OperatorLike, Values: []string{"10%"}},
+			condition: "(d.document_data #>> $1::TEXT[]) LIKE $2",
+			wantArgs:  []interface{}{pq.Array([]string{"title"}), "10%"},
+		},
+		{
+			name: "JSON in",
+			filter: domain.Filter{Field: "json.status", Kind: domain.FieldJSON, Path: []string{"status"},
+				Operator: domain.OperatorIn, Values: []string{"new", `"10"`, "10", "null"}},
+			condition: "(d.document_data #> $1::TEXT[]) = ANY($2::JSONB[])",
+			wantArgs:  []interface{}{pq.Array([]string{"status"}), pq.Array([]string{`"new"`, `"10"`, "10", "null"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args := filterCondition(tt.filter, tt.args)
+			if condition != tt.condition {
+				t.Errorf("filterCondition() = %q, want %q", condition, tt.condition)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("filterCondition() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFilterConditions(t *testing.T) {
+	filters := []domain.Filter{
+		{Field: "name", Kind: domain.FieldString, Operator: domain.OperatorEq, Values: []string{"a"}},
+		{Field: "is_public", Kind: domain.FieldBool, Operator: domain.OperatorNe, Values: []string{"true"}},
+	}
+
+	conditions, args := filterConditions(filters, []interface{}{"user"})
+
+	if want := " AND d.name = $2 AND d.is_public != $3"; conditions != want {
+		t.Errorf("filterConditions() = %q, want %q", conditions, want)
+	}
+
+	if want := []interface{}{"user", "a", "true"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("filterConditions() args = %#v, want %#v", args, want)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "10", want: "10"},
+		{value: "-1.5e3", want: "-1.5e3"},
+		{value: "true", want: "true"},
+		{value: "null", want: "null"},
+		{value: `"10"`, want: `"10"`},
+		{value: `{"a":1}`, want: `{"a":1}`},
+		{value: "[1,2]", want: "[1,2]"},
+		{value: "report", want: `"report"`},
+		{value: "", want: `""`},
+		{value: "True", want: `"True"`},
+		{value: "010", want: `"010"`},
+		{value: `say "hi"`, want: `"say \"hi\""`},
+		{value: `"unterminated`, want: `"\"unterminated"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := jsonValue(tt.value); got != tt.want {
+				t.Errorf("jsonValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	const id = "0b6f1f5e-4a4f-4c1e-9d55-1f0e8b3c2a10"
+
+	tests := []struct {
+		name      string
+		sort      []domain.Sort
+		cursor    domain.Cursor
+		args      []interface{} // args of the conditions before the cursor
+		condition string
+		wantArgs  []interface{}
+		err       error
+	}{
+		{
+			name:      "one field",
+			sort:      []domain.Sort{{Field: "created_at"}},
+			cursor:    domain.Cursor{Values: []string{"2024-01-31T10:00:00Z"}, Id: id},
+			condition: " AND ((d.created_at > $1) OR (d.created_at = $1 AND d.id > $2))",
+			wantArgs:  []interface{}{"2024-01-31T10:00:00Z", id},
+		},
+		{
+			name:      "descending after filters",
+			sort:      []domain.Sort{{Field: "name", Desc: true}},
+			cursor:    domain.Cursor{Values: []string{"report"}, Id: id},
+			args:      []interface{}{"user", "a%"},
+			condition: " AND ((d.name < $3) OR (d.name = $3 AND d.id > $4))",
+			wantArgs:  []interface{}{"user", "a%", "report", id},
+		},
+		{
+			name:   "several fields",
+			sort:   []domain.Sort{{Field: "is_file", Desc: true}, {Field: "version"}},
+			cursor: domain.Cursor{Values: []string{"true", "3"}, Id: id},
+			args:   []interface{}{"user"},
+			condition: " AND ((d.is_file < $2) OR (d.is_file = $2 AND d.version > $3)" +
+				" OR (d.is_file = $2 AND d.version = $3 AND d.id > $4))",
+			wantArgs: []interface{}{"user", "true", "3", id},
+		},
+		{
+			name:   "fewer values than the sort",
+			sort:   []domain.Sort{{Field: "name"}, {Field: "version"}},
+			cursor: domain.Cursor{Values: []string{"report"}, Id: id},
+			err:    domain.ErrInvalidCursor,
+		},
+		{
+			name:   "more values than the sort",
+			sort:   []domain.Sort{{Field: "name"}},
+			cursor: domain.Cursor{Values: []string{"report", "3"}, Id: id},
+			err:    domain.ErrInvalidCursor,
+		},
+		{
+			name:   "tampered int",
+			sort:   []domain.Sort{{Field: "version"}},
+			cursor: domain.Cursor{Values: []string{"3 OR 1=1"}, Id: id},
+			err:    domain.ErrInvalidCursor,
+		},
+		{
+			name:   "tampered time",
+			sort:   []domain.Sort{{Field: "updated_at"}},
+			cursor: domain.Cursor{Values: []string{"yesterday"}, Id: id},
+			err:    domain.ErrInvalidCursor,
+		},
+		{
+			name:   "tampered bool",
+			sort:   []domain.Sort{{Field: "is_public"}},
+			cursor: domain.Cursor{Values: []string{"maybe"}, Id: id},
+			err:    domain.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := keysetCondition(tt.sort, tt.cursor, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("keysetCondition() error = %v, want %v", err, tt.err)
+			}
+
+			if condition != tt.condition {
+				t.Errorf("keysetCondition() = %q, want %q", condition, tt.condition)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("keysetCondition() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
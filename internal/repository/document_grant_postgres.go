@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+func (r *DocumentPostgres) GetGrants(documentId, userId string) ([]domain.Grant, error) {
+	logger.Debugf("get document grants: params=[documentId=%v userId=%v]", documentId, userId)
+
+	query := `
+		SELECT 1
+		FROM documents d
+		WHERE
+			d.id = $1
+			AND ` + readAccessCondition + `
+	`
+
+	var exists bool
+	if err := r.db.Get(&exists, query, documentId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDocumentNotFound
+		}
+
+		logger.Errorf("failed to get document: %v", err)
+		return nil, err
+	}
+
+	query = `
+		SELECT
+			login,
+			group_name,
+			permission
+		FROM document_grants
+		WHERE document_id = $1
+		ORDER BY group_name, login
+	`
+
+	grants := make([]domain.Grant, 0)
+	if err := r.db.Select(&grants, query, documentId); err != nil {
+		logger.Errorf("failed to get grants: %v", err)
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// AddGrants - grants access to the document or changes the permission of existing grants,
+// returns subjects that don't belong to any user or group. Requires the share permission
+func (r *DocumentPostgres) AddGrants(documentId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	logger.Debugf("add document grants: params=[documentId=%v userId=%v grants=%v]", documentId, userId, grants)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	ownerId, err := lockDocument(tx, documentId, userId, domain.PermissionShare)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown, err = insertGrants(tx, documentId, ownerId, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// DeleteGrants - revokes access to the document from users and "@groups",
+// returns subjects that don't belong to any user or group.
+// Requires the share permission, the owner's own grant can't be revoked
+func (r *DocumentPostgres) DeleteGrants(documentId, userId string, subjects []string) (unknown []string, err error) {
+	logger.Debugf("delete document grants: params=[documentId=%v userId=%v subjects=%v]", documentId, userId, subjects)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	ownerId, err := lockDocument(tx, documentId, userId, domain.PermissionShare)
+	if err != nil {
+		return nil, err
+	}
+
+	logins, groups := splitSubjects(subjects)
+
+	unknown, err = unknownSubjects(tx, logins, groups)
+	if err != nil {
+		logger.Errorf("failed to check grant subjects: %v", err)
+		return nil, err
+	}
+
+	query := `
+		DELETE FROM access_grants ag
+		USING users u
+		WHERE
+			ag.user_id = u.id
+			AND ag.document_id = $1
+			AND u.login = ANY($2)
+			AND u.id != $3
+	`
+
+	if _, err := tx.Exec(query, documentId, pq.Array(logins), ownerId); err != nil {
+		logger.Errorf("failed to delete grants: documentId=%v: %v", documentId, err)
+		return nil, err
+	}
+
+	query = `
+		DELETE FROM group_grants gg
+		USING groups g
+		WHERE
+			gg.group_id = g.id
+			AND gg.document_id = $1
+			AND g.name = ANY($2)
+	`
+
+	if _, err := tx.Exec(query, documentId, pq.Array(groups)); err != nil {
+		logger.Errorf("failed to delete group grants: documentId=%v: %v", documentId, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// lockDocument - locks the document row and checks that the user has the permission on it,
+// returns the document owner id
+func lockDocument(tx *sqlx.Tx, documentId, userId string, permission domain.Permission) (ownerId string, err error) {
+	return documentAccess(tx, documentId, userId, permission, "FOR UPDATE")
+}
+
+// checkDocument - checks that the user has the permission on the document, returns the document owner id
+func checkDocument(q sqlx.Queryer, documentId, userId string, permission domain.Permission) (ownerId string, err error) {
+	return documentAccess(q, documentId, userId, permission, "")
+}
+
+func documentAccess(q sqlx.Queryer, documentId, userId string, permission domain.Permission, lock string) (string, error) {
+	query := `
+		SELECT
+			d.user_id,
+			document_permission(d.id, $2) AS rank
+		FROM documents d
+		WHERE d.id = $1
+		` + lock
+
+	var document struct {
+		UserId string `db:"user_id"`
+		Rank   int    `db:"rank"`
+	}
+	if err := sqlx.Get(q, &document, query, documentId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrDocumentNotFound
+		}
+
+		logger.Errorf("failed to get document: %v", err)
+		return "", err
+	}
+
+	if document.Rank < domain.PermissionRead.Rank() {
+		return "", domain.ErrDocumentNotFound
+	}
+
+	if document.Rank < permission.Rank() {
+		return "", domain.ErrPermissionDenied
+	}
+
+	return document.UserId, nil
+}
+
+// insertGrants - grants access to the document for users and groups,
+// returns subjects that don't belong to any user or group
+func insertGrants(tx *sqlx.Tx, documentId, ownerId string, grants []domain.Grant) ([]string, error) {
+	if len(grants) == 0 {
+		return nil, nil
+	}
+
+	var logins, loginPermissions, groups, groupPermissions []string
+	for _, grant := range grants {
+		if grant.Group != "" {
+			groups = append(groups, grant.Group)
+			groupPermissions = append(groupPermissions, string(grant.Permission))
+		} else {
+			logins = append(logins, grant.Login)
+			loginPermissions = append(loginPermissions, string(grant.Permission))
+		}
+	}
+
+	unknown, err := unknownSubjects(tx, logins, groups)
+	if err != nil {
+		logger.Errorf("failed to check grant subjects: %v", err)
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO access_grants (document_id, user_id, permission)
+		SELECT $1, u.id, g.permission
+		FROM UNNEST($2::VARCHAR[], $3::VARCHAR[]) AS g(login, permission)
+		JOIN users u ON u.login = g.login
+		WHERE u.id != $4
+		ON CONFLICT (document_id, user_id) DO UPDATE
+		SET permission = EXCLUDED.permission
+	`
+
+	if _, err := tx.Exec(query, documentId, pq.Array(logins), pq.Array(loginPermissions), ownerId); err != nil {
+		logger.Errorf("failed to insert grants: grants=%v, documentId=%v: %v", grants, documentId, err)
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO group_grants (document_id, group_id, permission)
+		SELECT $1, gr.id, g.permission
+		FROM UNNEST($2::VARCHAR[], $3::VARCHAR[]) AS g(name, permission)
+		JOIN groups gr ON gr.name = g.name
+		ON CONFLICT (document_id, group_id) DO UPDATE
+		SET permission = EXCLUDED.permission
+	`
+
+	if _, err := tx.Exec(query, documentId, pq.Array(groups), pq.Array(groupPermissions)); err != nil {
+		logger.Errorf("failed to insert group grants: grants=%v, documentId=%v: %v", grants, documentId, err)
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// splitSubjects - splits grant subjects into logins and group names
+func splitSubjects(subjects []string) (logins, groups []string) {
+	for _, subject := range subjects {
+		if group, ok := strings.CutPrefix(subject, domain.GroupPrefix); ok {
+			groups = append(groups, group)
+		} else {
+			logins = append(logins, subject)
+		}
+	}
+
+	return logins, groups
+}
+
+// unknownSubjects - returns logins that don't belong to any user and "@groups" that don't exist
+func unknownSubjects(tx *sqlx.Tx, logins, groups []string) ([]string, error) {
+	query := `
+		SELECT l.login
+		FROM UNNEST($1::VARCHAR[]) AS l(login)
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM users u
+			WHERE u.login = l.login
+		)
+		UNION ALL
+		SELECT '` + domain.GroupPrefix + `' || n.name
+		FROM UNNEST($2::VARCHAR[]) AS n(name)
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM groups g
+			WHERE g.name = n.name
+		)
+	`
+
+	unknown := make([]string, 0)
+	if err := tx.Select(&unknown, query, pq.Array(logins), pq.Array(groups)); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// CreateShareLink - saves the share link of the document. Requires the share permission
+func (r *DocumentPostgres) CreateShareLink(documentId, userId string, link *domain.ShareLink) error {
+	logger.Debugf("create share link: params=[documentId=%v userId=%v expiresAt=%v maxDownloads=%v]",
+		documentId, userId, link.ExpiresAt, link.MaxDownloads)
+
+	if _, err := checkDocument(r.db, documentId, userId, domain.PermissionShare); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO share_links (
+			document_id,
+			token_hash,
+			created_by,
+			max_downloads,
+			expires_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+		RETURNING id, created_at
+	`
+
+	if err := r.db.QueryRowx(query, documentId, link.TokenHash, userId, link.MaxDownloads, link.ExpiresAt).
+		Scan(&link.Id, &link.CreatedAt); err != nil {
+		logger.Errorf("failed to insert share link: documentId=%v: %v", documentId, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetShareLinks - returns share links of the document, newest first. Requires the share permission
+func (r *DocumentPostgres) GetShareLinks(documentId, userId string) ([]domain.ShareLink, error) {
+	logger.Debugf("get share links: params=[documentId=%v userId=%v]", documentId, userId)
+
+	if _, err := checkDocument(r.db, documentId, userId, domain.PermissionShare); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			id,
+			max_downloads,
+			downloads,
+			created_at,
+			expires_at
+		FROM share_links
+		WHERE document_id = $1
+		ORDER BY created_at DESC
+	`
+
+	links := make([]domain.ShareLink, 0)
+	if err := r.db.Select(&links, query, documentId); err != nil {
+		logger.Errorf("failed to get share links: %v", err)
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// DeleteShareLink - revokes the share link of the document. Requires the share permission
+func (r *DocumentPostgres) DeleteShareLink(documentId, userId, linkId string) error {
+	logger.Debugf("delete share link: params=[documentId=%v userId=%v linkId=%v]", documentId, userId, linkId)
+
+	if _, err := checkDocument(r.db, documentId, userId, domain.PermissionShare); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM share_links
+		WHERE id = $1 AND document_id = $2
+	`
+
+	result, err := r.db.Exec(query, linkId, documentId)
+	if err != nil {
+		logger.Errorf("failed to delete share link: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrShareLinkNotFound
+	}
+
+	return nil
+}
+
+// UseShareLink - counts a download by the share link with the token hash and returns the shared document.
+// Expired links and links with exhausted downloads return ErrShareLinkExpired, links of creators
+// who may no longer share the document return ErrShareLinkNotFound
+func (r *DocumentPostgres) UseShareLink(tokenHash string) (*domain.Document, error) {
+	logger.Debugf("use share link")
+
+	// The limit is checked and the counter is bumped by the same statement,
+	// so concurrent downloads can't exceed it. The link works only while its creator may share the document
+	query := `
+		UPDATE share_links
+		SET downloads = downloads + 1
+		WHERE
+			token_hash = $1
+			AND expires_at > NOW()
+			AND (max_downloads IS NULL OR downloads < max_downloads)
+			AND document_permission(document_id, created_by) >= $2
+		RETURNING document_id
+	`
+
+	shareRank := domain.PermissionShare.Rank()
+
+	var documentId string
+	if err := r.db.Get(&documentId, query, tokenHash, shareRank); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("failed to use share link: %v", err)
+			return nil, err
+		}
+
+		query = `
+			SELECT EXISTS (
+				SELECT 1
+				FROM share_links
+				WHERE token_hash = $1 AND document_permission(document_id, created_by) >= $2
+			)
+		`
+
+		var exists bool
+		if err := r.db.Get(&exists, query, tokenHash, shareRank); err != nil {
+			logger.Errorf("failed to get share link: %v", err)
+			return nil, err
+		}
+
+		if exists {
+			return nil, domain.ErrShareLinkExpired
+		}
+
+		return nil, domain.ErrShareLinkNotFound
+	}
+
+	query = `
+		SELECT
+			d.id,
+			d.name,
+			d.mime,
+			d.mime_mismatch,
+			d.file_path,
+			d.is_file,
+			d.is_public,
+			COALESCE(d.document_data::TEXT, '') AS document_data,
+			COALESCE(d.type, '') AS type,
+			d.version,
+			d.folder_id,
+			d.created_at,
+			d.updated_at
+		FROM documents d
+		WHERE d.id = $1
+	`
+
+	var document domain.Document
+	if err := r.db.Get(&document, query, documentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrShareLinkNotFound
+		}
+
+		logger.Errorf("failed to get document: %v", err)
+		return nil, err
+	}
+
+	return &document, nil
+}
@@ -8,10 +8,22 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/sixojke/test-astral/domain"
 	"github.com/sixojke/test-astral/pkg/logger"
 )
 
+// readAccessCondition - documents d the user $2 can read: public, own or granted
+var readAccessCondition = accessCondition(domain.PermissionRead)
+
+// grantSubject - login of the user or "@group" of the document_grants row g
+const grantSubject = `CASE WHEN g.group_name = '' THEN g.login ELSE '` + domain.GroupPrefix + `' || g.group_name END`
+
+// accessCondition - documents d the user $2 has at least the given permission on
+func accessCondition(permission domain.Permission) string {
+	return fmt.Sprintf("document_permission(d.id, $2) >= %d", permission.Rank())
+}
+
 type DocumentPostgres struct {
 	db *sqlx.DB
 }
@@ -28,12 +40,16 @@ type docByUserIdHelp struct {
 	Id           string    `db:"id"`
 	Name         string    `db:"name"`
 	Mime         string    `db:"mime"`
+	MimeMismatch bool      `db:"mime_mismatch"`
 	FilePath     string    `db:"file_path"`
 	IsFile       bool      `db:"is_file"`
 	IsPublic     bool      `db:"is_public"`
 	DocumentData string    `db:"document_data"`
+	Version      int       `db:"version"`
+	FolderId     *string   `db:"folder_id"`
 	Grants       string    `db:"grants"`
 	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
 }
 
 func (d *docsByUserIdHelp) prepare() *[]domain.Document {
@@ -44,27 +60,47 @@ func (d *docsByUserIdHelp) prepare() *[]domain.Document {
 			Id:           doc.Id,
 			Name:         doc.Name,
 			Mime:         doc.Mime,
+			MimeMismatch: doc.MimeMismatch,
 			FilePath:     doc.FilePath,
 			IsFile:       doc.IsFile,
-			IsPublic:     doc.IsFile,
+			IsPublic:     doc.IsPublic,
 			DocumentData: doc.DocumentData,
-			Grants:       strings.Split(doc.Grants, ","),
+			Version:      doc.Version,
+			FolderId:     doc.FolderId,
+			Grants:       parseGrants(doc.Grants),
 			CreatedAt:    doc.CreatedAt,
+			UpdatedAt:    doc.UpdatedAt,
 		})
 	}
 
 	return &docs
 }
 
-func (r *DocumentPostgres) Create(document *domain.Document, userId string) error {
+// parseGrants - parses grants aggregated as "subject:permission,subject:permission"
+func parseGrants(aggregated string) []domain.Grant {
+	grants := make([]domain.Grant, 0)
+	if aggregated == "" {
+		return grants
+	}
+
+	for _, grant := range strings.Split(aggregated, ",") {
+		subject, permission, _ := strings.Cut(grant, ":")
+		grants = append(grants, domain.NewGrant(subject, domain.Permission(permission)))
+	}
+
+	return grants
+}
+
+// Create - creates the document, returns grant logins that don't belong to any user
+func (r *DocumentPostgres) Create(document *domain.Document, userId string) (unknownGrants []string, err error) {
 	logger.Debugf("create document: params=[%v]", *document)
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.Beginx()
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer func() {
-		if rerr := tx.Rollback(); rerr != nil && err == nil {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
 			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
 		}
 	}()
@@ -73,186 +109,160 @@ func (r *DocumentPostgres) Create(document *domain.Document, userId string) erro
 		INSERT INTO documents (
 		   	name,
 		   	mime,
+			mime_mismatch,
 		   	file_path,
 			is_file,
 		   	is_public,
 		   	document_data,
-		   	user_id
+		   	user_id,
+			folder_id,
+			type
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
+			$1, $2, $3, $4, $5, $6, NULLIF($7, '')::JSONB, $8, $9, NULLIF($10, '')
 	  	) RETURNING
 			id
 	`
 
+	if document.FolderId != nil {
+		if err := checkFolderOwner(tx, *document.FolderId, userId); err != nil {
+			return nil, err
+		}
+	}
+
 	var documentId string
-	if err := tx.QueryRow(query, document.Name, document.Mime, document.FilePath, document.IsFile,
-		document.IsPublic, document.DocumentData, userId).Scan(&documentId); err != nil {
+	if err := tx.QueryRow(query, document.Name, document.Mime, document.MimeMismatch, document.FilePath, document.IsFile,
+		document.IsPublic, document.DocumentData, userId, document.FolderId, document.Type).Scan(&documentId); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" && pqErr.Constraint == "documents_type_fkey" {
+			return nil, domain.ErrSchemaNotFound
+		}
+
 		logger.Errorf("failed to insert document: %v", err)
-		return err
+		return nil, err
+	}
+
+	if document.FilePath != "" {
+		if err := acquireBlob(tx, document.FilePath); err != nil {
+			logger.Errorf("failed to acquire blob: key=%v: %v", document.FilePath, err)
+			return nil, err
+		}
 	}
 
 	query = `
 		INSERT INTO access_grants (
 			document_id,
-			user_id
+			user_id,
+			permission
 		) VALUES (
-			$1, $2
+			$1, $2, $3
 		)
 	`
 
-	if _, err := tx.Exec(query, documentId, userId); err != nil {
+	if _, err := tx.Exec(query, documentId, userId, domain.PermissionShare); err != nil {
 		logger.Errorf("failed to insert grant: grant=%v, documentId=%v: %v", userId, documentId, err)
-		return err
-	}
-
-	if len(document.Grants) > 0 {
-		for _, grant := range document.Grants {
-			query = `
-		  		WITH user_info AS (
-					SELECT id
-					FROM users
-					WHERE login = $1 AND id != $2
-		  		)
-		  		INSERT INTO access_grants (document_id, user_id)
-		  		SELECT $3, id
-		  		FROM user_info;
-			`
-
-			if _, err := tx.Exec(query, grant, userId, documentId); err != nil {
-				logger.Errorf("failed to insert grant: grant=%v, documentId=%v: %v", grant, documentId, err)
-				return err
-			}
-		}
+		return nil, err
 	}
 
-	return tx.Commit()
-}
-
-func (r *DocumentPostgres) GetCurrentUserDocuments(currentUserId string, params *domain.FilterParams) (*[]domain.Document, error) {
-	logger.Debugf("get current user documents: params=[currentUserId=%v params=%v]", currentUserId, *params)
-
-	query := `
-	  SELECT 
-		  d.id,
-		  d.name,
-		  d.mime,
-		  d.file_path,
-		  d.is_file,
-		  d.is_public,
-		  COALESCE(STRING_AGG(u.login, ','), '') AS grants,
-		  d.created_at
-	  FROM documents d
-	  LEFT JOIN access_grants ag ON d.id = ag.document_id
-	  LEFT JOIN users u ON ag.user_id = u.id
-	  WHERE d.user_id = $1
-	`
-
-	args := []interface{}{currentUserId}
+	unknownGrants, err = insertGrants(tx, documentId, userId, document.Grants)
+	if err != nil {
+		return nil, err
+	}
 
-	if params.Key != "" && params.Value != "" && isValidField(params.Key) {
-		query += " AND d." + params.Key + " LIKE $" + fmt.Sprintf("%d", len(args)+1)
-		args = append(args, params.Value)
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	query += `
-	  GROUP BY d.id, d.name, d.mime, d.file_path, d.is_file, d.is_public, d.document_data, d.created_at
-	  ORDER BY d.created_at ASC
-	  LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2) + `;
-	`
+	document.Id = documentId
+
+	return unknownGrants, nil
+}
 
-	args = append(args, params.Limit, params.Offset)
+func (r *DocumentPostgres) GetCurrentUserDocuments(currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
+	logger.Debugf("get current user documents: params=[currentUserId=%v params=%v]", currentUserId, *params)
 
-	var docsDirty docsByUserIdHelp
-	if err := r.db.Select(&docsDirty, query, args...); err != nil {
-		logger.Errorf("failed to get current user documents: %v", err)
+	list, err := r.listDocuments("d.user_id = $1", []interface{}{currentUserId}, params, defaultListSort)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidCursor) {
+			logger.Errorf("failed to get current user documents: %v", err)
+		}
 		return nil, err
 	}
 
-	return docsDirty.prepare(), nil
+	return list, nil
 }
 
-func (r *DocumentPostgres) GetOtherUserDocuments(userId string, currentUserId string, params *domain.FilterParams) (*[]domain.Document, error) {
+func (r *DocumentPostgres) GetOtherUserDocuments(userId string, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
 	logger.Debugf("get other user documents: params=[userId=%v currentUserId=%v params=%v]", userId, currentUserId, *params)
 
-	query := `
-	  SELECT 
-		d.id,
-		d.name,
-		d.mime,
-		d.file_path,
-		d.is_file,
-		d.is_public,
-		COALESCE(STRING_AGG(u.login, ','), '') AS grants
-	  FROM documents d
-	  JOIN access_grants ag ON d.id = ag.document_id
-	  JOIN users u ON ag.user_id = u.id
-	  WHERE 
+	where := `
 		d.user_id = $1
-		AND d.id IN (
-		  SELECT ag.document_id
-		  FROM access_grants ag
-		  WHERE ag.user_id = $2
-		) 
-		OR (
-			d.user_id = $1
-			AND d.is_public = true
-		)
-	`
+		AND ` + readAccessCondition
 
-	args := []interface{}{userId, currentUserId}
-
-	if params.Key != "" && params.Value != "" && isValidField(params.Key) {
-		query += " AND d." + params.Key + " LIKE $" + fmt.Sprintf("%d", len(args)+1)
-		args = append(args, params.Value)
+	list, err := r.listDocuments(where, []interface{}{userId, currentUserId}, params, defaultListSort)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidCursor) {
+			logger.Errorf("failed to get other user documents: %v", err)
+		}
+		return nil, err
 	}
 
-	query += `
-	  GROUP BY d.id, d.name, d.mime, d.file_path, d.is_file, d.is_public, d.document_data
-	  ORDER BY d.created_at ASC
-	  LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2) + `;
-	`
+	return list, nil
+}
 
-	args = append(args, params.Limit, params.Offset)
+// GetFolderDocuments - returns documents of the folder the user can read. Requires the read permission on the folder
+func (r *DocumentPostgres) GetFolderDocuments(folderId, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
+	logger.Debugf("get folder documents: params=[folderId=%v currentUserId=%v params=%v]", folderId, currentUserId, *params)
 
-	var docsDirty docsByUserIdHelp
-	if err := r.db.Select(&docsDirty, query, args...); err != nil {
-		logger.Errorf("failed to get other user documents: %v", err)
+	if _, err := checkFolder(r.db, folderId, currentUserId, domain.PermissionRead); err != nil {
 		return nil, err
 	}
 
-	return docsDirty.prepare(), nil
+	where := `
+		d.folder_id = $1
+		AND ` + readAccessCondition
+
+	list, err := r.listDocuments(where, []interface{}{folderId, currentUserId}, params, defaultFolderSort)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidCursor) {
+			logger.Errorf("failed to get folder documents: %v", err)
+		}
+		return nil, err
+	}
+
+	return list, nil
 }
 
 func (r *DocumentPostgres) GetById(documentId, userId string) (*domain.Document, error) {
 	logger.Debugf("get document by id: params=[documentId=%v userId=%v]", documentId, userId)
 
+	return getDocument(r.db, documentId, userId)
+}
+
+// getDocument - returns the document with its grants if the user can read it
+func getDocument(q sqlx.Queryer, documentId, userId string) (*domain.Document, error) {
 	query := `
 		SELECT 
 			d.id,
 			d.name,
 			d.mime,
+			d.mime_mismatch,
 			d.file_path,
 			d.is_file,
 			d.is_public,
-			d.document_data,
-			d.created_at
+			COALESCE(d.document_data::TEXT, '') AS document_data,
+			COALESCE(d.type, '') AS type,
+			d.version,
+			d.folder_id,
+			d.created_at,
+			d.updated_at
   		FROM documents d
   		WHERE 
 	  		d.id = $1
-	  		AND (
-				d.is_public = TRUE 
-	  			OR d.user_id = $2
-				OR EXISTS (
-					SELECT 1
-					FROM access_grants ag
-					WHERE ag.document_id = d.id
-					AND ag.user_id = $3
-				)
-	  		)
+	  		AND ` + readAccessCondition + `
 	`
 
 	var document domain.Document
-	if err := r.db.Get(&document, query, documentId, userId, userId); err != nil {
+	if err := sqlx.Get(q, &document, query, documentId, userId); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrDocumentNotFound
 		}
@@ -262,14 +272,17 @@ func (r *DocumentPostgres) GetById(documentId, userId string) (*domain.Document,
 	}
 
 	query = `
-		SELECT u.login
-		FROM access_grants ag
-		JOIN users u ON ag.user_id = u.id
-		WHERE ag.document_id = $1;
+		SELECT
+			login,
+			group_name,
+			permission
+		FROM document_grants
+		WHERE document_id = $1
+		ORDER BY login, group_name;
 	`
 
-	var grants []string
-	if err := r.db.Select(&grants, query, documentId); err != nil {
+	var grants []domain.Grant
+	if err := sqlx.Select(q, &grants, query, documentId); err != nil {
 		logger.Errorf("failed to get grants: %v", err)
 		return nil, err
 	}
@@ -279,54 +292,376 @@ func (r *DocumentPostgres) GetById(documentId, userId string) (*domain.Document,
 	return &document, nil
 }
 
-func (r *DocumentPostgres) CheckById(documentId, userId string) (bool, error) {
-	logger.Debugf("check document by id: params=[documentId=%v userId=%v]", documentId, userId)
+// Update - updates document metadata, returns grant logins that don't belong to any user.
+// Requires the write permission, changing grants or publicity requires the share permission.
+// A change of the data or type is validated with the schema and data the document will have, under the lock
+func (r *DocumentPostgres) Update(documentId, userId string, update *domain.DocumentUpdate,
+	validate func(schema, data string) error) (unknownGrants []string, err error) {
+	logger.Debugf("update document: params=[documentId=%v userId=%v update=%+v]", documentId, userId, *update)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	// Changing the audience of the document requires the share permission,
+	// moving it between folders changes inherited grants and is left to the owner
+	permission := domain.PermissionWrite
+	if update.Grants != nil || update.IsPublic != nil {
+		permission = domain.PermissionShare
+	}
+	if update.FolderId != nil {
+		permission = domain.PermissionOwner
+	}
+
+	ownerId, err := lockDocument(tx, documentId, userId, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DocumentData != nil || update.Type != nil {
+		query := `
+			SELECT
+				COALESCE(type, '') AS type,
+				COALESCE(document_data::TEXT, '') AS document_data
+			FROM documents
+			WHERE id = $1
+		`
+
+		var current struct {
+			Type         string `db:"type"`
+			DocumentData string `db:"document_data"`
+		}
+		if err := tx.Get(&current, query, documentId); err != nil {
+			logger.Errorf("failed to get document: %v", err)
+			return nil, err
+		}
+
+		schema, data := current.Type, current.DocumentData
+		if update.Type != nil {
+			schema = *update.Type
+		}
+		if update.DocumentData != nil {
+			data = *update.DocumentData
+		}
+
+		if schema != "" {
+			if err := validate(schema, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if update.DocumentData != nil {
+		// JSON is compared as JSONB, so formatting changes don't make a version
+		query := `
+			SELECT document_data IS DISTINCT FROM NULLIF($2, '')::JSONB
+			FROM documents
+			WHERE id = $1
+		`
+
+		var changed bool
+		if err := tx.Get(&changed, query, documentId, *update.DocumentData); err != nil {
+			logger.Errorf("failed to get document: %v", err)
+			return nil, err
+		}
+
+		if changed {
+			if err := saveVersion(tx, documentId); err != nil {
+				logger.Errorf("failed to save document version: documentId=%v: %v", documentId, err)
+				return nil, err
+			}
+		}
+	}
 
 	query := `
-	SELECT 
-		1
-	FROM documents d
-	WHERE 
-		  d.id = $1
-		  AND (
-			d.is_public = TRUE 
-			OR EXISTS (
-				  SELECT 1
-				  FROM access_grants ag
-				  WHERE ag.document_id = d.id
-				  AND ag.user_id = $2
-			)
-		  )
-`
-
-	var exists bool
-	if err := r.db.Get(&exists, query, documentId, userId); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, domain.ErrDocumentNotFound
+		UPDATE documents
+		SET
+			name = COALESCE($1, name),
+			mime = COALESCE($2, mime),
+			mime_mismatch = COALESCE($3, mime_mismatch),
+			is_public = COALESCE($4, is_public),
+			document_data = CASE WHEN $5::TEXT IS NULL THEN document_data ELSE NULLIF($5, '')::JSONB END,
+			type = CASE WHEN $6::TEXT IS NULL THEN type ELSE NULLIF($6, '') END,
+			updated_at = NOW()
+		WHERE id = $7
+	`
+
+	if _, err := tx.Exec(query, update.Name, update.Mime, update.MimeMismatch, update.IsPublic, update.DocumentData,
+		update.Type, documentId); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" && pqErr.Constraint == "documents_type_fkey" {
+			return nil, domain.ErrSchemaNotFound
+		}
+
+		logger.Errorf("failed to update document: %v", err)
+		return nil, err
+	}
+
+	if update.FolderId != nil {
+		var folderId *string
+		if *update.FolderId != "" {
+			if err := checkFolderOwner(tx, *update.FolderId, ownerId); err != nil {
+				return nil, err
+			}
+
+			folderId = update.FolderId
+		}
+
+		query = `
+			UPDATE documents
+			SET folder_id = $1
+			WHERE id = $2
+		`
+
+		if _, err := tx.Exec(query, folderId, documentId); err != nil {
+			logger.Errorf("failed to move document: %v", err)
+			return nil, err
+		}
+	}
+
+	if update.Grants != nil {
+		query = `
+			DELETE FROM access_grants
+			WHERE document_id = $1 AND user_id != $2
+		`
+
+		if _, err := tx.Exec(query, documentId, ownerId); err != nil {
+			logger.Errorf("failed to delete grants: documentId=%v: %v", documentId, err)
+			return nil, err
+		}
+
+		query = `
+			DELETE FROM group_grants
+			WHERE document_id = $1
+		`
+
+		if _, err := tx.Exec(query, documentId); err != nil {
+			logger.Errorf("failed to delete group grants: documentId=%v: %v", documentId, err)
+			return nil, err
+		}
+
+		unknownGrants, err = insertGrants(tx, documentId, ownerId, *update.Grants)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknownGrants, nil
+}
+
+// UpdateData - replaces the JSON data of the document with the data returned by the update for the locked document,
+// so the update sees the type and data no one can change until the new data is saved. Requires the write permission
+func (r *DocumentPostgres) UpdateData(documentId, userId string,
+	update func(document *domain.Document) (data string, err error)) (newVersion int, err error) {
+	logger.Debugf("update document data: params=[documentId=%v userId=%v]", documentId, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
 		}
+	}()
+
+	if _, err := lockDocument(tx, documentId, userId, domain.PermissionWrite); err != nil {
+		return 0, err
+	}
+
+	document, err := getDocument(tx, documentId, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := update(document)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		SELECT document_data IS DISTINCT FROM NULLIF($2, '')::JSONB
+		FROM documents
+		WHERE id = $1
+	`
+
+	var changed bool
+	if err := tx.Get(&changed, query, documentId, data); err != nil {
+		logger.Errorf("failed to get document: %v", err)
+		return 0, err
+	}
+
+	if !changed {
+		return document.Version, nil
+	}
+
+	if err := saveVersion(tx, documentId); err != nil {
+		logger.Errorf("failed to save document version: documentId=%v: %v", documentId, err)
+		return 0, err
+	}
+
+	query = `
+		UPDATE documents
+		SET
+			document_data = NULLIF($1, '')::JSONB,
+			updated_at = NOW()
+		WHERE id = $2
+		RETURNING version
+	`
+
+	if err := tx.Get(&newVersion, query, data, documentId); err != nil {
+		logger.Errorf("failed to update document data: %v", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return newVersion, nil
+}
+
+// UpdateFile - replaces the file of the document, returns the file path of the previous blob
+// if the blob is no longer referenced. Requires the write permission
+func (r *DocumentPostgres) UpdateFile(documentId, userId, filePath, mime string, mimeMismatch bool) (oldFilePath string, err error) {
+	logger.Debugf("update document file: params=[documentId=%v userId=%v filePath=%v mime=%v mimeMismatch=%v]",
+		documentId, userId, filePath, mime, mimeMismatch)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return "", fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if _, err := lockDocument(tx, documentId, userId, domain.PermissionWrite); err != nil {
+		return "", err
+	}
+
+	query := `
+		SELECT
+			file_path,
+			is_file
+		FROM documents
+		WHERE id = $1
+	`
 
+	var current struct {
+		FilePath string `db:"file_path"`
+		IsFile   bool   `db:"is_file"`
+	}
+	if err := tx.Get(&current, query, documentId); err != nil {
 		logger.Errorf("failed to get document: %v", err)
-		return false, err
+		return "", err
+	}
+
+	if !current.IsFile {
+		return "", domain.ErrDocumentIsNotFile
+	}
+
+	if err := saveVersion(tx, documentId); err != nil {
+		logger.Errorf("failed to save document version: documentId=%v: %v", documentId, err)
+		return "", err
+	}
+
+	query = `
+		UPDATE documents
+		SET
+			file_path = $1,
+			mime = $2,
+			mime_mismatch = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`
+
+	if _, err := tx.Exec(query, filePath, mime, mimeMismatch, documentId); err != nil {
+		logger.Errorf("failed to update document file: %v", err)
+		return "", err
+	}
+
+	if err := acquireBlob(tx, filePath); err != nil {
+		logger.Errorf("failed to acquire blob: key=%v: %v", filePath, err)
+		return "", err
+	}
+
+	orphaned := false
+	if current.FilePath != "" {
+		if orphaned, err = releaseBlob(tx, current.FilePath); err != nil {
+			logger.Errorf("failed to release blob: key=%v: %v", current.FilePath, err)
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	if !orphaned {
+		return "", nil
 	}
 
-	return exists, nil
+	return current.FilePath, nil
 }
 
-func (r *DocumentPostgres) Delete(documentId, userId string) (filePath string, err error) {
+// Delete - deletes the document with its versions, returns file paths of blobs that are no longer referenced
+func (r *DocumentPostgres) Delete(documentId, userId string) (filePaths []string, err error) {
 	logger.Debugf("delete document: params=[documentId=%v]", documentId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	// The document is locked, so a concurrent file update or restore can't add a file after the paths are read
 	query := `
-		SELECT file_path
+		SELECT id
 		FROM documents
 		WHERE id = $1 AND user_id = $2
+		FOR UPDATE
 	`
 
-	if err := r.db.Get(&filePath, query, documentId, userId); err != nil {
+	if err := tx.Get(&documentId, query, documentId, userId); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", domain.ErrDocumentNotFound
+			return nil, domain.ErrDocumentNotFound
 		}
 
-		logger.Errorf("failed to get file_path: %v", err)
-		return "", err
+		logger.Errorf("failed to lock document: %v", err)
+		return nil, err
+	}
+
+	query = `
+		SELECT file_path
+		FROM documents
+		WHERE id = $1 AND user_id = $2 AND file_path != ''
+		UNION ALL
+		SELECT v.file_path
+		FROM document_versions v
+		JOIN documents d ON d.id = v.document_id
+		WHERE v.document_id = $1 AND d.user_id = $2 AND v.file_path != ''
+	`
+
+	var referenced []string
+	if err := tx.Select(&referenced, query, documentId, userId); err != nil {
+		logger.Errorf("failed to get file paths: %v", err)
+		return nil, err
 	}
 
 	query = `
@@ -334,21 +669,33 @@ func (r *DocumentPostgres) Delete(documentId, userId string) (filePath string, e
 		WHERE id = $1 AND user_id = $2
 	`
 
-	_, err = r.db.Exec(query, documentId, userId)
+	result, err := tx.Exec(query, documentId, userId)
 	if err != nil {
 		logger.Errorf("failed to delete document: %v", err)
-		return "", err
+		return nil, err
 	}
 
-	return filePath, nil
-}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, domain.ErrDocumentNotFound
+	}
 
-func isValidField(field string) bool {
-	validFields := []string{"name", "mime", "file_path", "is_file", "is_public", "document_data", "created_at"}
-	for _, v := range validFields {
-		if v == field {
-			return true
+	for _, filePath := range referenced {
+		orphaned, err := releaseBlob(tx, filePath)
+		if err != nil {
+			logger.Errorf("failed to release blob: key=%v: %v", filePath, err)
+			return nil, err
 		}
+
+		if orphaned {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
-	return false
+
+	return filePaths, nil
 }
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// searchConfig - text search configuration of documents.search_vector
+const searchConfig = "simple"
+
+// Search - finds documents the user can read by name, JSON data and text of files, the best matches first.
+// The query supports web search syntax: quoted phrases, OR and -word
+func (r *DocumentPostgres) Search(query, userId string, params *domain.FilterParams) ([]domain.SearchResult, error) {
+	logger.Debugf("search documents: params=[query=%v userId=%v params=%v]", query, userId, *params)
+
+	// Highlighting is expensive, so it's done only for the requested page
+	sqlQuery := `
+		WITH found AS (
+			SELECT
+				d.id,
+				d.name,
+				d.mime,
+				d.is_file,
+				d.is_public,
+				d.folder_id,
+				COALESCE(d.document_data::TEXT, d.content_text) AS body,
+				d.updated_at,
+				ts_rank(d.search_vector, q.query) AS rank,
+				q.query
+			FROM documents d, websearch_to_tsquery('` + searchConfig + `', $1) AS q(query)
+			WHERE
+				d.search_vector @@ q.query
+				AND ` + readAccessCondition + `
+			ORDER BY rank DESC, d.updated_at DESC
+			LIMIT $3 OFFSET $4
+		)
+		SELECT
+			id,
+			name,
+			mime,
+			is_file,
+			is_public,
+			folder_id,
+			updated_at,
+			rank,
+			ts_headline('` + searchConfig + `', ` + escapeHTML("name") + `, query, 'HighlightAll=true') AS name_highlight,
+			COALESCE(ts_headline('` + searchConfig + `', ` + escapeHTML("body") + `, query,
+				'MaxFragments=2, MaxWords=20, MinWords=5'), '') AS snippet
+		FROM found
+		ORDER BY rank DESC, updated_at DESC
+	`
+
+	results := make([]domain.SearchResult, 0)
+	if err := r.db.Select(&results, sqlQuery, query, userId, params.Limit, params.Offset); err != nil {
+		logger.Errorf("failed to search documents: %v", err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// escapeHTML - SQL expression escaping HTML special characters of the text. Highlighted fragments are HTML,
+// the user text in them is escaped so only the <b></b> of ts_headline are tags
+func escapeHTML(column string) string {
+	expr := column
+	for _, r := range [][2]string{
+		{"&", "&amp;"},
+		{"<", "&lt;"},
+		{">", "&gt;"},
+		{`"`, "&quot;"},
+		{"''", "&#39;"},
+	} {
+		expr = "REPLACE(" + expr + ", '" + r[0] + "', '" + r[1] + "')"
+	}
+
+	return expr
+}
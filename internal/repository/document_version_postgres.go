@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+func (r *DocumentPostgres) GetVersions(documentId, userId string) (*[]domain.DocumentVersion, error) {
+	logger.Debugf("get document versions: params=[documentId=%v userId=%v]", documentId, userId)
+
+	query := `
+		SELECT
+			d.version,
+			d.mime,
+			d.mime_mismatch,
+			d.file_path,
+			d.is_file,
+			TRUE AS current,
+			d.updated_at AS created_at
+		FROM documents d
+		WHERE
+			d.id = $1
+			AND ` + readAccessCondition + `
+		UNION ALL
+		SELECT
+			v.version,
+			v.mime,
+			v.mime_mismatch,
+			v.file_path,
+			d.is_file,
+			FALSE AS current,
+			v.created_at
+		FROM document_versions v
+		JOIN documents d ON d.id = v.document_id
+		WHERE
+			v.document_id = $1
+			AND ` + readAccessCondition + `
+		ORDER BY version DESC
+	`
+
+	versions := make([]domain.DocumentVersion, 0)
+	if err := r.db.Select(&versions, query, documentId, userId); err != nil {
+		logger.Errorf("failed to get document versions: %v", err)
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, domain.ErrDocumentNotFound
+	}
+
+	return &versions, nil
+}
+
+func (r *DocumentPostgres) GetVersion(documentId, userId string, version int) (*domain.DocumentVersion, error) {
+	logger.Debugf("get document version: params=[documentId=%v userId=%v version=%v]", documentId, userId, version)
+
+	query := `
+		SELECT
+			d.version,
+			d.mime,
+			d.mime_mismatch,
+			d.file_path,
+			d.is_file,
+			COALESCE(d.document_data::TEXT, '') AS document_data,
+			TRUE AS current,
+			d.updated_at AS created_at
+		FROM documents d
+		WHERE
+			d.id = $1
+			AND d.version = $3
+			AND ` + readAccessCondition + `
+		UNION ALL
+		SELECT
+			v.version,
+			v.mime,
+			v.mime_mismatch,
+			v.file_path,
+			d.is_file,
+			COALESCE(v.document_data::TEXT, '') AS document_data,
+			FALSE AS current,
+			v.created_at
+		FROM document_versions v
+		JOIN documents d ON d.id = v.document_id
+		WHERE
+			v.document_id = $1
+			AND v.version = $3
+			AND ` + readAccessCondition + `
+	`
+
+	var documentVersion domain.DocumentVersion
+	if err := r.db.Get(&documentVersion, query, documentId, userId, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrVersionNotFound
+		}
+
+		logger.Errorf("failed to get document version: %v", err)
+		return nil, err
+	}
+
+	return &documentVersion, nil
+}
+
+// RestoreVersion - makes the content of the version current, the replaced content is saved as a new version.
+// Requires the write permission
+func (r *DocumentPostgres) RestoreVersion(documentId, userId string, version int) (err error) {
+	logger.Debugf("restore document version: params=[documentId=%v userId=%v version=%v]", documentId, userId, version)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if _, err := lockDocument(tx, documentId, userId, domain.PermissionWrite); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT
+			file_path,
+			version
+		FROM documents
+		WHERE id = $1
+	`
+
+	var current struct {
+		FilePath string `db:"file_path"`
+		Version  int    `db:"version"`
+	}
+	if err := tx.Get(&current, query, documentId); err != nil {
+		logger.Errorf("failed to get document: %v", err)
+		return err
+	}
+
+	if current.Version == version {
+		return nil
+	}
+
+	query = `
+		SELECT
+			mime,
+			mime_mismatch,
+			file_path,
+			document_data
+		FROM document_versions
+		WHERE document_id = $1 AND version = $2
+	`
+
+	var restored struct {
+		Mime         string  `db:"mime"`
+		MimeMismatch bool    `db:"mime_mismatch"`
+		FilePath     string  `db:"file_path"`
+		DocumentData *string `db:"document_data"`
+	}
+	if err := tx.Get(&restored, query, documentId, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrVersionNotFound
+		}
+
+		logger.Errorf("failed to get document version: %v", err)
+		return err
+	}
+
+	if err := saveVersion(tx, documentId); err != nil {
+		logger.Errorf("failed to save document version: documentId=%v: %v", documentId, err)
+		return err
+	}
+
+	query = `
+		UPDATE documents
+		SET
+			mime = $1,
+			mime_mismatch = $2,
+			file_path = $3,
+			document_data = $4,
+			updated_at = NOW()
+		WHERE id = $5
+	`
+
+	if _, err := tx.Exec(query, restored.Mime, restored.MimeMismatch, restored.FilePath, restored.DocumentData,
+		documentId); err != nil {
+		logger.Errorf("failed to restore document version: %v", err)
+		return err
+	}
+
+	if restored.FilePath != "" {
+		if err := acquireBlob(tx, restored.FilePath); err != nil {
+			logger.Errorf("failed to acquire blob: key=%v: %v", restored.FilePath, err)
+			return err
+		}
+	}
+
+	// The replaced file is still referenced by the saved version
+	if current.FilePath != "" {
+		if _, err := releaseBlob(tx, current.FilePath); err != nil {
+			logger.Errorf("failed to release blob: key=%v: %v", current.FilePath, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// saveVersion - saves the current content of the document as a version and bumps the document version
+func saveVersion(tx *sqlx.Tx, documentId string) error {
+	query := `
+		INSERT INTO document_versions (
+			document_id,
+			version,
+			mime,
+			mime_mismatch,
+			file_path,
+			document_data,
+			created_at
+		)
+		SELECT
+			id,
+			version,
+			mime,
+			mime_mismatch,
+			file_path,
+			document_data,
+			updated_at
+		FROM documents
+		WHERE id = $1
+		RETURNING file_path
+	`
+
+	var filePath string
+	if err := tx.Get(&filePath, query, documentId); err != nil {
+		return err
+	}
+
+	if filePath != "" {
+		if err := acquireBlob(tx, filePath); err != nil {
+			return err
+		}
+	}
+
+	query = `
+		UPDATE documents
+		SET version = version + 1
+		WHERE id = $1
+	`
+
+	_, err := tx.Exec(query, documentId)
+
+	return err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/cache"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// FolderCache - drops the document cache when folder grants or the tree change,
+// since documents inherit grants of their folders. Folders themselves aren't cached
+type FolderCache struct {
+	Folder
+	cache *cache.Cache
+}
+
+func NewFolderCache(repo Folder, cache *cache.Cache) *FolderCache {
+	return &FolderCache{
+		Folder: repo,
+		cache:  cache,
+	}
+}
+
+func (r *FolderCache) Update(folderId, userId string, update *domain.FolderUpdate) error {
+	if err := r.Folder.Update(folderId, userId, update); err != nil {
+		return err
+	}
+
+	if update.ParentId != nil {
+		r.purge(folderId)
+	}
+
+	return nil
+}
+
+func (r *FolderCache) AddGrants(folderId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	unknown, err = r.Folder.AddGrants(folderId, userId, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	r.purge(folderId)
+
+	return unknown, nil
+}
+
+func (r *FolderCache) DeleteGrants(folderId, userId string, subjects []string) (unknown []string, err error) {
+	unknown, err = r.Folder.DeleteGrants(folderId, userId, subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	r.purge(folderId)
+
+	return unknown, nil
+}
+
+// purge - drops the whole document cache, documents under the folder aren't known here
+func (r *FolderCache) purge(folderId string) {
+	logger.Debugf("purge document cache: params=[folderId=%v]", folderId)
+
+	r.cache.Purge()
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// GetGrants - returns grants given on the folder itself, grants of its ancestors aren't included
+func (r *FolderPostgres) GetGrants(folderId, userId string) ([]domain.Grant, error) {
+	logger.Debugf("get folder grants: params=[folderId=%v userId=%v]", folderId, userId)
+
+	if _, err := checkFolder(r.db, folderId, userId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			COALESCE(u.login, '') AS login,
+			COALESCE(g.name, '') AS group_name,
+			fg.permission
+		FROM folder_grants fg
+		LEFT JOIN users u ON fg.user_id = u.id
+		LEFT JOIN groups g ON fg.group_id = g.id
+		WHERE fg.folder_id = $1
+		ORDER BY group_name, login
+	`
+
+	grants := make([]domain.Grant, 0)
+	if err := r.db.Select(&grants, query, folderId); err != nil {
+		logger.Errorf("failed to get folder grants: %v", err)
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// AddGrants - grants access to the folder and everything inside it, returns subjects
+// that don't belong to any user or group. Requires the share permission
+func (r *FolderPostgres) AddGrants(folderId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	logger.Debugf("add folder grants: params=[folderId=%v userId=%v grants=%v]", folderId, userId, grants)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	ownerId, err := lockFolder(tx, folderId, userId, domain.PermissionShare)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown, err = insertFolderGrants(tx, folderId, ownerId, grants)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// DeleteGrants - revokes access to the folder from users and "@groups",
+// returns subjects that don't belong to any user or group. Requires the share permission
+func (r *FolderPostgres) DeleteGrants(folderId, userId string, subjects []string) (unknown []string, err error) {
+	logger.Debugf("delete folder grants: params=[folderId=%v userId=%v subjects=%v]", folderId, userId, subjects)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if _, err := lockFolder(tx, folderId, userId, domain.PermissionShare); err != nil {
+		return nil, err
+	}
+
+	logins, groups := splitSubjects(subjects)
+
+	unknown, err = unknownSubjects(tx, logins, groups)
+	if err != nil {
+		logger.Errorf("failed to check grant subjects: %v", err)
+		return nil, err
+	}
+
+	query := `
+		DELETE FROM folder_grants fg
+		WHERE
+			fg.folder_id = $1
+			AND (
+				fg.user_id IN (SELECT id FROM users WHERE login = ANY($2))
+				OR fg.group_id IN (SELECT id FROM groups WHERE name = ANY($3))
+			)
+	`
+
+	if _, err := tx.Exec(query, folderId, pq.Array(logins), pq.Array(groups)); err != nil {
+		logger.Errorf("failed to delete folder grants: folderId=%v: %v", folderId, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// insertFolderGrants - grants access to the folder for users and groups,
+// returns subjects that don't belong to any user or group
+func insertFolderGrants(tx *sqlx.Tx, folderId, ownerId string, grants []domain.Grant) ([]string, error) {
+	var logins, loginPermissions, groups, groupPermissions []string
+	for _, grant := range grants {
+		if grant.Group != "" {
+			groups = append(groups, grant.Group)
+			groupPermissions = append(groupPermissions, string(grant.Permission))
+		} else {
+			logins = append(logins, grant.Login)
+			loginPermissions = append(loginPermissions, string(grant.Permission))
+		}
+	}
+
+	unknown, err := unknownSubjects(tx, logins, groups)
+	if err != nil {
+		logger.Errorf("failed to check grant subjects: %v", err)
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO folder_grants (folder_id, user_id, permission)
+		SELECT $1, u.id, g.permission
+		FROM UNNEST($2::VARCHAR[], $3::VARCHAR[]) AS g(login, permission)
+		JOIN users u ON u.login = g.login
+		WHERE u.id != $4
+		ON CONFLICT (folder_id, user_id) WHERE user_id IS NOT NULL DO UPDATE
+		SET permission = EXCLUDED.permission
+	`
+
+	if _, err := tx.Exec(query, folderId, pq.Array(logins), pq.Array(loginPermissions), ownerId); err != nil {
+		logger.Errorf("failed to insert folder grants: grants=%v, folderId=%v: %v", grants, folderId, err)
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO folder_grants (folder_id, group_id, permission)
+		SELECT $1, gr.id, g.permission
+		FROM UNNEST($2::VARCHAR[], $3::VARCHAR[]) AS g(name, permission)
+		JOIN groups gr ON gr.name = g.name
+		ON CONFLICT (folder_id, group_id) WHERE group_id IS NOT NULL DO UPDATE
+		SET permission = EXCLUDED.permission
+	`
+
+	if _, err := tx.Exec(query, folderId, pq.Array(groups), pq.Array(groupPermissions)); err != nil {
+		logger.Errorf("failed to insert folder group grants: grants=%v, folderId=%v: %v", grants, folderId, err)
+		return nil, err
+	}
+
+	return unknown, nil
+}
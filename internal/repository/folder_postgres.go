@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// folderReadCondition - folders f the user $2 can read: own or granted on the folder or its ancestors
+var folderReadCondition = fmt.Sprintf("folder_permission(f.id, $2) >= %d", domain.PermissionRead.Rank())
+
+type FolderPostgres struct {
+	db *sqlx.DB
+}
+
+func NewFolderPostgres(db *sqlx.DB) *FolderPostgres {
+	return &FolderPostgres{
+		db: db,
+	}
+}
+
+// Create - creates the folder, subfolders can be created only in own folders
+func (r *FolderPostgres) Create(folder *domain.Folder, userId string) (err error) {
+	logger.Debugf("create folder: params=[name=%v parentId=%v userId=%v]", folder.Name, folder.ParentId, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if folder.ParentId != nil {
+		if err := checkFolderOwner(tx, *folder.ParentId, userId); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO folders (
+			name,
+			parent_id,
+			user_id
+		) VALUES (
+			$1, $2, $3
+		)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := tx.QueryRowx(query, folder.Name, folder.ParentId, userId).
+		Scan(&folder.Id, &folder.CreatedAt, &folder.UpdatedAt); err != nil {
+		logger.Errorf("failed to create folder: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByUser - returns all folders of the user, the tree is built by parent ids
+func (r *FolderPostgres) GetByUser(userId string) ([]domain.Folder, error) {
+	logger.Debugf("get user folders: params=[userId=%v]", userId)
+
+	query := `
+		SELECT
+			id,
+			name,
+			parent_id,
+			created_at,
+			updated_at
+		FROM folders
+		WHERE user_id = $1
+		ORDER BY name
+	`
+
+	folders := make([]domain.Folder, 0)
+	if err := r.db.Select(&folders, query, userId); err != nil {
+		logger.Errorf("failed to get user folders: %v", err)
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// GetById - returns the folder if the user owns it or has a grant on it or on its ancestors
+func (r *FolderPostgres) GetById(folderId, userId string) (*domain.Folder, error) {
+	logger.Debugf("get folder by id: params=[folderId=%v userId=%v]", folderId, userId)
+
+	query := `
+		SELECT
+			f.id,
+			f.name,
+			f.parent_id,
+			f.created_at,
+			f.updated_at
+		FROM folders f
+		WHERE
+			f.id = $1
+			AND ` + folderReadCondition + `
+	`
+
+	var folder domain.Folder
+	if err := r.db.Get(&folder, query, folderId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFolderNotFound
+		}
+
+		logger.Errorf("failed to get folder: %v", err)
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// GetChildren - returns subfolders of the folder. Requires the read permission on the folder
+func (r *FolderPostgres) GetChildren(folderId, userId string) ([]domain.Folder, error) {
+	logger.Debugf("get folder children: params=[folderId=%v userId=%v]", folderId, userId)
+
+	if _, err := checkFolder(r.db, folderId, userId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			id,
+			name,
+			parent_id,
+			created_at,
+			updated_at
+		FROM folders
+		WHERE parent_id = $1
+		ORDER BY name
+	`
+
+	folders := make([]domain.Folder, 0)
+	if err := r.db.Select(&folders, query, folderId); err != nil {
+		logger.Errorf("failed to get folder children: %v", err)
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// Update - renames or moves the folder. Only the owner can change the folder,
+// the folder can't be moved into itself or its subfolders
+func (r *FolderPostgres) Update(folderId, userId string, update *domain.FolderUpdate) (err error) {
+	logger.Debugf("update folder: params=[folderId=%v userId=%v update=%+v]", folderId, userId, *update)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if _, err := lockFolder(tx, folderId, userId, domain.PermissionOwner); err != nil {
+		return err
+	}
+
+	if update.ParentId != nil {
+		// Moves of one user's folders are serialized, otherwise two concurrent moves could make a cycle
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, userId); err != nil {
+			logger.Errorf("failed to lock user folders: %v", err)
+			return err
+		}
+
+		var parentId *string
+		if *update.ParentId != "" {
+			if err := checkFolderOwner(tx, *update.ParentId, userId); err != nil {
+				return err
+			}
+
+			query := `
+				WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id
+					FROM folders
+					WHERE id = $1
+					UNION ALL
+					SELECT f.id, f.parent_id
+					FROM folders f
+					JOIN ancestors a ON f.id = a.parent_id
+				)
+				SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+			`
+
+			var cycle bool
+			if err := tx.Get(&cycle, query, *update.ParentId, folderId); err != nil {
+				logger.Errorf("failed to check folder ancestors: %v", err)
+				return err
+			}
+
+			if cycle {
+				return domain.ErrFolderCycle
+			}
+
+			parentId = update.ParentId
+		}
+
+		query := `
+			UPDATE folders
+			SET parent_id = $1
+			WHERE id = $2
+		`
+
+		if _, err := tx.Exec(query, parentId, folderId); err != nil {
+			logger.Errorf("failed to move folder: %v", err)
+			return err
+		}
+	}
+
+	query := `
+		UPDATE folders
+		SET
+			name = COALESCE($1, name),
+			updated_at = NOW()
+		WHERE id = $2
+	`
+
+	if _, err := tx.Exec(query, update.Name, folderId); err != nil {
+		logger.Errorf("failed to update folder: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete - deletes the empty folder. Only the owner can delete the folder
+func (r *FolderPostgres) Delete(folderId, userId string) (err error) {
+	logger.Debugf("delete folder: params=[folderId=%v userId=%v]", folderId, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if _, err := lockFolder(tx, folderId, userId, domain.PermissionOwner); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM folders WHERE parent_id = $1)
+			OR EXISTS (SELECT 1 FROM documents WHERE folder_id = $1)
+	`
+
+	var notEmpty bool
+	if err := tx.Get(&notEmpty, query, folderId); err != nil {
+		logger.Errorf("failed to check folder contents: %v", err)
+		return err
+	}
+
+	if notEmpty {
+		return domain.ErrFolderIsNotEmpty
+	}
+
+	if _, err := tx.Exec(`DELETE FROM folders WHERE id = $1`, folderId); err != nil {
+		logger.Errorf("failed to delete folder: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockFolder - locks the folder row and checks that the user has the permission on it,
+// returns the folder owner id
+func lockFolder(tx *sqlx.Tx, folderId, userId string, permission domain.Permission) (ownerId string, err error) {
+	return folderAccess(tx, folderId, userId, permission, "FOR UPDATE")
+}
+
+// checkFolder - checks that the user has the permission on the folder, returns the folder owner id
+func checkFolder(q sqlx.Queryer, folderId, userId string, permission domain.Permission) (ownerId string, err error) {
+	return folderAccess(q, folderId, userId, permission, "")
+}
+
+func folderAccess(q sqlx.Queryer, folderId, userId string, permission domain.Permission, lock string) (string, error) {
+	query := `
+		SELECT
+			f.user_id,
+			folder_permission(f.id, $2) AS rank
+		FROM folders f
+		WHERE f.id = $1
+		` + lock
+
+	var folder struct {
+		UserId string `db:"user_id"`
+		Rank   int    `db:"rank"`
+	}
+	if err := sqlx.Get(q, &folder, query, folderId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrFolderNotFound
+		}
+
+		logger.Errorf("failed to get folder: %v", err)
+		return "", err
+	}
+
+	if folder.Rank < domain.PermissionRead.Rank() {
+		return "", domain.ErrFolderNotFound
+	}
+
+	if folder.Rank < permission.Rank() {
+		return "", domain.ErrPermissionDenied
+	}
+
+	return folder.UserId, nil
+}
+
+// checkFolderOwner - checks that the folder belongs to the user, documents and subfolders
+// can be put only into own folders
+func checkFolderOwner(tx *sqlx.Tx, folderId, userId string) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM folders
+			WHERE id = $1 AND user_id = $2
+		)
+	`
+
+	var owned bool
+	if err := tx.Get(&owned, query, folderId, userId); err != nil {
+		logger.Errorf("failed to get folder: %v", err)
+		return err
+	}
+
+	if !owned {
+		return domain.ErrFolderNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/sixojke/test-astral/pkg/cache"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// GroupCache - drops the document cache when group membership or name changes,
+// since it changes what members can see. Groups themselves aren't cached
+type GroupCache struct {
+	Group
+	cache *cache.Cache
+}
+
+func NewGroupCache(repo Group, cache *cache.Cache) *GroupCache {
+	return &GroupCache{
+		Group: repo,
+		cache: cache,
+	}
+}
+
+func (r *GroupCache) Delete(groupId, userId string) error {
+	if err := r.Group.Delete(groupId, userId); err != nil {
+		return err
+	}
+
+	r.purge(groupId)
+
+	return nil
+}
+
+// Rename - cached documents show the group name in their grants
+func (r *GroupCache) Rename(groupId, userId, name string) error {
+	if err := r.Group.Rename(groupId, userId, name); err != nil {
+		return err
+	}
+
+	r.purge(groupId)
+
+	return nil
+}
+
+func (r *GroupCache) AddMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	unknown, err = r.Group.AddMembers(groupId, userId, logins)
+	if err != nil {
+		return nil, err
+	}
+
+	r.purge(groupId)
+
+	return unknown, nil
+}
+
+func (r *GroupCache) DeleteMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	unknown, err = r.Group.DeleteMembers(groupId, userId, logins)
+	if err != nil {
+		return nil, err
+	}
+
+	r.purge(groupId)
+
+	return unknown, nil
+}
+
+// purge - drops the whole document cache, documents granted to the group aren't known here
+func (r *GroupCache) purge(groupId string) {
+	logger.Debugf("purge document cache: params=[groupId=%v]", groupId)
+
+	r.cache.Purge()
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+type GroupPostgres struct {
+	db *sqlx.DB
+}
+
+func NewGroupPostgres(db *sqlx.DB) *GroupPostgres {
+	return &GroupPostgres{
+		db: db,
+	}
+}
+
+// Create - creates the group, the owner becomes its member. Returns member logins that don't belong to any user
+func (r *GroupPostgres) Create(group *domain.Group, ownerId string, members []string) (unknown []string, err error) {
+	logger.Debugf("create group: params=[name=%v ownerId=%v members=%v]", group.Name, ownerId, members)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	query := `
+		INSERT INTO groups (
+			name,
+			owner_id
+		) VALUES (
+			$1, $2
+		)
+		RETURNING id, created_at
+	`
+
+	if err := tx.QueryRowx(query, group.Name, ownerId).Scan(&group.Id, &group.CreatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, domain.ErrGroupNameIsBusy
+		}
+
+		logger.Errorf("failed to create group: %v", err)
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO group_members (
+			group_id,
+			user_id
+		) VALUES (
+			$1, $2
+		)
+	`
+
+	if _, err := tx.Exec(query, group.Id, ownerId); err != nil {
+		logger.Errorf("failed to insert group owner: groupId=%v: %v", group.Id, err)
+		return nil, err
+	}
+
+	unknown, err = insertMembers(tx, group.Id, members)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// GetByUser - returns groups the user owns or is a member of
+func (r *GroupPostgres) GetByUser(userId string) ([]domain.Group, error) {
+	logger.Debugf("get user groups: params=[userId=%v]", userId)
+
+	query := `
+		SELECT
+			g.id,
+			g.name,
+			u.login AS owner,
+			g.created_at
+		FROM groups g
+		JOIN users u ON g.owner_id = u.id
+		JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.user_id = $1
+		ORDER BY g.name
+	`
+
+	groups := make([]domain.Group, 0)
+	if err := r.db.Select(&groups, query, userId); err != nil {
+		logger.Errorf("failed to get user groups: %v", err)
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetById - returns the group with its members, only members can see the group
+func (r *GroupPostgres) GetById(groupId, userId string) (*domain.Group, error) {
+	logger.Debugf("get group by id: params=[groupId=%v userId=%v]", groupId, userId)
+
+	query := `
+		SELECT
+			g.id,
+			g.name,
+			u.login AS owner,
+			g.created_at
+		FROM groups g
+		JOIN users u ON g.owner_id = u.id
+		WHERE
+			g.id = $1
+			AND EXISTS (
+				SELECT 1
+				FROM group_members gm
+				WHERE gm.group_id = g.id AND gm.user_id = $2
+			)
+	`
+
+	var group domain.Group
+	if err := r.db.Get(&group, query, groupId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrGroupNotFound
+		}
+
+		logger.Errorf("failed to get group: %v", err)
+		return nil, err
+	}
+
+	query = `
+		SELECT u.login
+		FROM group_members gm
+		JOIN users u ON gm.user_id = u.id
+		WHERE gm.group_id = $1
+		ORDER BY u.login
+	`
+
+	members := make([]string, 0)
+	if err := r.db.Select(&members, query, groupId); err != nil {
+		logger.Errorf("failed to get group members: %v", err)
+		return nil, err
+	}
+
+	group.Members = members
+
+	return &group, nil
+}
+
+// Rename - changes the group name, existing grants keep working. Only the owner can rename the group
+func (r *GroupPostgres) Rename(groupId, userId, name string) (err error) {
+	logger.Debugf("rename group: params=[groupId=%v userId=%v name=%v]", groupId, userId, name)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockGroup(tx, groupId, userId); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE groups
+		SET name = $1
+		WHERE id = $2
+	`
+
+	if _, err := tx.Exec(query, name, groupId); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return domain.ErrGroupNameIsBusy
+		}
+
+		logger.Errorf("failed to rename group: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete - deletes the group with its grants. Only the owner can delete the group
+func (r *GroupPostgres) Delete(groupId, userId string) (err error) {
+	logger.Debugf("delete group: params=[groupId=%v userId=%v]", groupId, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockGroup(tx, groupId, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM groups WHERE id = $1`, groupId); err != nil {
+		logger.Errorf("failed to delete group: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddMembers - adds users to the group, returns logins that don't belong to any user.
+// Only the owner can manage members
+func (r *GroupPostgres) AddMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	logger.Debugf("add group members: params=[groupId=%v userId=%v logins=%v]", groupId, userId, logins)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockGroup(tx, groupId, userId); err != nil {
+		return nil, err
+	}
+
+	unknown, err = insertMembers(tx, groupId, logins)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// DeleteMembers - removes users from the group, returns logins that don't belong to any user.
+// Only the owner can manage members, the owner can't be removed
+func (r *GroupPostgres) DeleteMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	logger.Debugf("delete group members: params=[groupId=%v userId=%v logins=%v]", groupId, userId, logins)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockGroup(tx, groupId, userId); err != nil {
+		return nil, err
+	}
+
+	unknown, err = unknownSubjects(tx, logins, nil)
+	if err != nil {
+		logger.Errorf("failed to check logins: %v", err)
+		return nil, err
+	}
+
+	query := `
+		DELETE FROM group_members gm
+		USING users u
+		WHERE
+			gm.user_id = u.id
+			AND gm.group_id = $1
+			AND u.login = ANY($2)
+			AND u.id != $3
+	`
+
+	if _, err := tx.Exec(query, groupId, pq.Array(logins), userId); err != nil {
+		logger.Errorf("failed to delete group members: groupId=%v: %v", groupId, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
+
+// lockGroup - locks the group row and checks that the user owns it
+func lockGroup(tx *sqlx.Tx, groupId, userId string) error {
+	query := `
+		SELECT
+			g.owner_id,
+			EXISTS (
+				SELECT 1
+				FROM group_members gm
+				WHERE gm.group_id = g.id AND gm.user_id = $2
+			) AS member
+		FROM groups g
+		WHERE g.id = $1
+		FOR UPDATE
+	`
+
+	var group struct {
+		OwnerId string `db:"owner_id"`
+		Member  bool   `db:"member"`
+	}
+	if err := tx.Get(&group, query, groupId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrGroupNotFound
+		}
+
+		logger.Errorf("failed to get group: %v", err)
+		return err
+	}
+
+	if group.OwnerId == userId {
+		return nil
+	}
+
+	if !group.Member {
+		return domain.ErrGroupNotFound
+	}
+
+	return domain.ErrPermissionDenied
+}
+
+// insertMembers - adds users with the given logins to the group, returns logins that don't belong to any user
+func insertMembers(tx *sqlx.Tx, groupId string, logins []string) ([]string, error) {
+	if len(logins) == 0 {
+		return nil, nil
+	}
+
+	unknown, err := unknownSubjects(tx, logins, nil)
+	if err != nil {
+		logger.Errorf("failed to check logins: %v", err)
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO group_members (group_id, user_id)
+		SELECT $1, u.id
+		FROM users u
+		WHERE u.login = ANY($2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := tx.Exec(query, groupId, pq.Array(logins)); err != nil {
+		logger.Errorf("failed to insert group members: logins=%v, groupId=%v: %v", logins, groupId, err)
+		return nil, err
+	}
+
+	return unknown, nil
+}
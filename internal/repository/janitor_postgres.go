@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+type JanitorPostgres struct {
+	db *sqlx.DB
+}
+
+func NewJanitorPostgres(db *sqlx.DB) *JanitorPostgres {
+	return &JanitorPostgres{
+		db: db,
+	}
+}
+
+// DeleteExpiredSessions - deletes expired sessions with their tokens, returns the number of deleted sessions
+func (r *JanitorPostgres) DeleteExpiredSessions(limit int) (int64, error) {
+	logger.Debugf("delete expired sessions: params=[limit=%v]", limit)
+
+	query := `
+		DELETE FROM sessions
+		WHERE id IN (
+			SELECT id
+			FROM sessions
+			WHERE expires_at < NOW()
+			LIMIT $1
+		)
+	`
+
+	result, err := r.db.Exec(query, limit)
+	if err != nil {
+		logger.Errorf("failed to delete expired sessions: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpiredTokens - deletes expired access tokens, tokens without expiration are never accepted and deleted too.
+// Returns the number of deleted tokens
+func (r *JanitorPostgres) DeleteExpiredTokens(limit int) (int64, error) {
+	logger.Debugf("delete expired tokens: params=[limit=%v]", limit)
+
+	query := `
+		DELETE FROM tokens
+		WHERE ctid IN (
+			SELECT ctid
+			FROM tokens
+			WHERE expires_at IS NULL OR expires_at < NOW()
+			LIMIT $1
+		)
+	`
+
+	result, err := r.db.Exec(query, limit)
+	if err != nil {
+		logger.Errorf("failed to delete expired tokens: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteUsedRefreshTokens - deletes refresh tokens used before the time, they are kept to detect their reuse.
+// Returns the number of deleted tokens
+func (r *JanitorPostgres) DeleteUsedRefreshTokens(usedBefore time.Time, limit int) (int64, error) {
+	logger.Debugf("delete used refresh tokens: params=[usedBefore=%v limit=%v]", usedBefore, limit)
+
+	query := `
+		DELETE FROM refresh_tokens
+		WHERE token_hash IN (
+			SELECT token_hash
+			FROM refresh_tokens
+			WHERE used_at < $1
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.Exec(query, usedBefore, limit)
+	if err != nil {
+		logger.Errorf("failed to delete used refresh tokens: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetReleasedBlobs - returns keys of blobs without references after the key in the key order.
+// Their files weren't deleted when the last reference was released
+func (r *JanitorPostgres) GetReleasedBlobs(after string, limit int) ([]string, error) {
+	logger.Debugf("get released blobs: params=[after=%v limit=%v]", after, limit)
+
+	query := `
+		SELECT key
+		FROM blobs
+		WHERE
+			ref_count <= 0
+			AND key > $1
+		ORDER BY key
+		LIMIT $2
+	`
+
+	keys := make([]string, 0)
+	if err := r.db.Select(&keys, query, after, limit); err != nil {
+		logger.Errorf("failed to get released blobs: %v", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetUnreferenced - returns the blob keys that no document, version or upload references
+func (r *JanitorPostgres) GetUnreferenced(blobKeys []string) ([]string, error) {
+	logger.Debugf("get unreferenced blobs: params=[blobKeys=%v]", len(blobKeys))
+
+	query := `
+		SELECT k.key
+		FROM UNNEST($1::VARCHAR[]) AS k(key)
+		WHERE
+			NOT EXISTS (SELECT 1 FROM blobs b WHERE b.key = k.key AND b.ref_count > 0)
+			AND NOT EXISTS (SELECT 1 FROM documents d WHERE d.file_path = k.key)
+			AND NOT EXISTS (SELECT 1 FROM document_versions v WHERE v.file_path = k.key)
+			AND NOT EXISTS (SELECT 1 FROM upload_chunks c WHERE c.blob_key = k.key)
+	`
+
+	unreferenced := make([]string, 0)
+	if err := r.db.Select(&unreferenced, query, pq.Array(blobKeys)); err != nil {
+		logger.Errorf("failed to get unreferenced blobs: %v", err)
+		return nil, err
+	}
+
+	return unreferenced, nil
+}
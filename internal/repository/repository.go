@@ -1,40 +1,152 @@
 package repository
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/cache"
 )
 
 type User interface {
 	Create(login, password string) error
-	GetByCredentials(login, password string) (string, error)
-	AddSession(session domain.Session) error
-	GetUserIdBySession(session string) (userId string, err error)
-	DeleteSession(session string) error
+	GetCredentials(login string) (userId, passwordHash string, err error)
+	UpdatePassword(userId, passwordHash string) error
+	CreateSession(session *domain.Session, tokens *domain.SessionTokens) error
+	RotateTokens(session *domain.Session, tokens *domain.SessionTokens) (revoked []domain.RevokedToken, err error)
+	UseRefreshToken(refreshTokenHash string) (*domain.Session, error)
+	GetSessions(userId string) ([]domain.Session, error)
+	DeleteUserSession(sessionId, userId string) error
+	DeleteUserSessions(userId string) (sessionIds []string, err error)
+	GetUserIdBySession(tokenHash string) (userId string, err error)
+	DeleteSession(tokenHash, userId string) error
 	GetUserIdByLogin(login string) (string, error)
 }
 
 type Document interface {
-	Create(document *domain.Document, userId string) error
-	GetCurrentUserDocuments(currentUserId string, params *domain.FilterParams) (*[]domain.Document, error)
-	GetOtherUserDocuments(userId string, currentUserId string, params *domain.FilterParams) (*[]domain.Document, error)
+	Create(document *domain.Document, userId string) (unknownGrants []string, err error)
+	GetCurrentUserDocuments(currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error)
+	GetOtherUserDocuments(userId string, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error)
+	GetFolderDocuments(folderId, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error)
+	Search(query, userId string, params *domain.FilterParams) ([]domain.SearchResult, error)
 	GetById(documentId, userId string) (*domain.Document, error)
-	CheckById(documentId, userId string) (bool, error)
-	Delete(documentId, userId string) (filePath string, err error)
+	Update(documentId, userId string, update *domain.DocumentUpdate,
+		validate func(schema, data string) error) (unknownGrants []string, err error)
+	UpdateData(documentId, userId string,
+		update func(document *domain.Document) (data string, err error)) (newVersion int, err error)
+	UpdateFile(documentId, userId, filePath, mime string, mimeMismatch bool) (oldFilePath string, err error)
+	Delete(documentId, userId string) (filePaths []string, err error)
+	GetVersions(documentId, userId string) (*[]domain.DocumentVersion, error)
+	GetVersion(documentId, userId string, version int) (*domain.DocumentVersion, error)
+	RestoreVersion(documentId, userId string, version int) error
+	GetGrants(documentId, userId string) ([]domain.Grant, error)
+	AddGrants(documentId, userId string, grants []domain.Grant) (unknown []string, err error)
+	DeleteGrants(documentId, userId string, subjects []string) (unknown []string, err error)
+	CreateShareLink(documentId, userId string, link *domain.ShareLink) error
+	GetShareLinks(documentId, userId string) ([]domain.ShareLink, error)
+	DeleteShareLink(documentId, userId, linkId string) error
+	UseShareLink(tokenHash string) (*domain.Document, error)
+}
+
+type Group interface {
+	Create(group *domain.Group, ownerId string, members []string) (unknown []string, err error)
+	GetByUser(userId string) ([]domain.Group, error)
+	GetById(groupId, userId string) (*domain.Group, error)
+	Rename(groupId, userId, name string) error
+	Delete(groupId, userId string) error
+	AddMembers(groupId, userId string, logins []string) (unknown []string, err error)
+	DeleteMembers(groupId, userId string, logins []string) (unknown []string, err error)
+}
+
+type Folder interface {
+	Create(folder *domain.Folder, userId string) error
+	GetByUser(userId string) ([]domain.Folder, error)
+	GetById(folderId, userId string) (*domain.Folder, error)
+	GetChildren(folderId, userId string) ([]domain.Folder, error)
+	Update(folderId, userId string, update *domain.FolderUpdate) error
+	Delete(folderId, userId string) error
+	GetGrants(folderId, userId string) ([]domain.Grant, error)
+	AddGrants(folderId, userId string, grants []domain.Grant) (unknown []string, err error)
+	DeleteGrants(folderId, userId string, subjects []string) (unknown []string, err error)
+}
+
+type Content interface {
+	GetPending(limit int) ([]string, error)
+	GetById(documentId string) (*domain.DocumentContent, error)
+	Set(documentId, filePath string, text *string) error
+}
+
+type Schema interface {
+	Create(schema *domain.Schema, ownerId string) error
+	GetAll() ([]domain.Schema, error)
+	GetByName(name string) (*domain.Schema, error)
+	Update(name, userId string, schema json.RawMessage) error
+	Delete(name, userId string) error
+}
+
+type Upload interface {
+	Create(upload *domain.Upload, userId string) error
+	GetById(uploadId, userId string) (*domain.Upload, error)
+	AddChunk(uploadId, userId string, chunk *domain.UploadChunk, expiresAt time.Time) (offset int64, err error)
+	StartCompleting(uploadId, userId string, expiresAt time.Time) (*domain.Upload, []domain.UploadChunk, error)
+	CancelCompleting(uploadId string) error
+	FinishCompleting(uploadId, userId string) (blobKeys []string, err error)
+	Delete(uploadId, userId string) (blobKeys []string, err error)
+	DeleteExpired(limit int) (uploads int, blobKeys []string, err error)
+}
+
+type Blob interface {
+	Acquire(key string) error
+	Release(key string) (orphaned bool, err error)
+	Delete(key string, deleteFile func(key string) error) (deleted bool, err error)
+	StripKeyPrefix(prefix string) (documents int64, err error)
+}
+
+type Janitor interface {
+	DeleteExpiredSessions(limit int) (int64, error)
+	DeleteExpiredTokens(limit int) (int64, error)
+	DeleteUsedRefreshTokens(usedBefore time.Time, limit int) (int64, error)
+	GetReleasedBlobs(after string, limit int) ([]string, error)
+	GetUnreferenced(blobKeys []string) ([]string, error)
 }
 
 type Deps struct {
-	Postgres *sqlx.DB
+	Postgres      *sqlx.DB
+	DocumentCache *cache.Cache
 }
 
 type Repository struct {
 	User
 	Document
+	Group
+	Folder
+	Content
+	Schema
+	Upload
+	Blob
+	Janitor
 }
 
 func NewService(deps *Deps) *Repository {
+	var document Document = NewDocumentPostgres(deps.Postgres)
+	var group Group = NewGroupPostgres(deps.Postgres)
+	var folder Folder = NewFolderPostgres(deps.Postgres)
+	if deps.DocumentCache != nil {
+		document = NewDocumentCache(document, deps.DocumentCache)
+		group = NewGroupCache(group, deps.DocumentCache)
+		folder = NewFolderCache(folder, deps.DocumentCache)
+	}
+
 	return &Repository{
 		NewUserPostgres(deps.Postgres),
-		NewDocumentPostgres(deps.Postgres),
+		document,
+		group,
+		folder,
+		NewContentPostgres(deps.Postgres),
+		NewSchemaPostgres(deps.Postgres),
+		NewUploadPostgres(deps.Postgres),
+		NewBlobPostgres(deps.Postgres),
+		NewJanitorPostgres(deps.Postgres),
 	}
 }
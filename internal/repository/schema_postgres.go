@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+type SchemaPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSchemaPostgres(db *sqlx.DB) *SchemaPostgres {
+	return &SchemaPostgres{
+		db: db,
+	}
+}
+
+// Create - registers the schema, schema names are unique across all users
+func (r *SchemaPostgres) Create(schema *domain.Schema, ownerId string) error {
+	logger.Debugf("create schema: params=[name=%v ownerId=%v]", schema.Name, ownerId)
+
+	query := `
+		INSERT INTO json_schemas (
+			name,
+			schema,
+			owner_id
+		) VALUES (
+			$1, $2, $3
+		)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := r.db.QueryRowx(query, schema.Name, []byte(schema.Schema), ownerId).
+		Scan(&schema.Id, &schema.CreatedAt, &schema.UpdatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return domain.ErrSchemaNameIsBusy
+		}
+
+		logger.Errorf("failed to create schema: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetAll - returns all schemas without their bodies
+func (r *SchemaPostgres) GetAll() ([]domain.Schema, error) {
+	logger.Debug("get schemas")
+
+	query := `
+		SELECT
+			s.id,
+			s.name,
+			COALESCE(u.login, '') AS owner,
+			s.created_at,
+			s.updated_at
+		FROM json_schemas s
+		LEFT JOIN users u ON s.owner_id = u.id
+		ORDER BY s.name
+	`
+
+	schemas := make([]domain.Schema, 0)
+	if err := r.db.Select(&schemas, query); err != nil {
+		logger.Errorf("failed to get schemas: %v", err)
+		return nil, err
+	}
+
+	return schemas, nil
+}
+
+func (r *SchemaPostgres) GetByName(name string) (*domain.Schema, error) {
+	logger.Debugf("get schema: params=[name=%v]", name)
+
+	query := `
+		SELECT
+			s.id,
+			s.name,
+			COALESCE(u.login, '') AS owner,
+			s.schema,
+			s.created_at,
+			s.updated_at
+		FROM json_schemas s
+		LEFT JOIN users u ON s.owner_id = u.id
+		WHERE s.name = $1
+	`
+
+	var schema domain.Schema
+	if err := r.db.Get(&schema, query, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSchemaNotFound
+		}
+
+		logger.Errorf("failed to get schema: %v", err)
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// Update - replaces the schema, documents that are already stored aren't validated again.
+// Only the owner can change the schema
+func (r *SchemaPostgres) Update(name, userId string, schema json.RawMessage) (err error) {
+	logger.Debugf("update schema: params=[name=%v userId=%v]", name, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockSchema(tx, name, userId); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE json_schemas
+		SET
+			schema = $1,
+			updated_at = NOW()
+		WHERE name = $2
+	`
+
+	if _, err := tx.Exec(query, []byte(schema), name); err != nil {
+		logger.Errorf("failed to update schema: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete - deletes the schema if no document refers to it. Only the owner can delete the schema
+func (r *SchemaPostgres) Delete(name, userId string) (err error) {
+	logger.Debugf("delete schema: params=[name=%v userId=%v]", name, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	if err := lockSchema(tx, name, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM json_schemas WHERE name = $1`, name); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return domain.ErrSchemaIsUsed
+		}
+
+		logger.Errorf("failed to delete schema: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockSchema - locks the schema row and checks that the user owns it
+func lockSchema(tx *sqlx.Tx, name, userId string) error {
+	query := `
+		SELECT owner_id
+		FROM json_schemas
+		WHERE name = $1
+		FOR UPDATE
+	`
+
+	var ownerId sql.NullString
+	if err := tx.Get(&ownerId, query, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrSchemaNotFound
+		}
+
+		logger.Errorf("failed to get schema: %v", err)
+		return err
+	}
+
+	if ownerId.String != userId {
+		return domain.ErrPermissionDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+// CreateSession - creates the session with its first tokens, the session id is set by the caller
+// since the access token refers to it
+func (r *UserPostgres) CreateSession(session *domain.Session, tokens *domain.SessionTokens) (err error) {
+	logger.Debugf("create session: params=[sessionId=%v userId=%v]", session.Id, session.UserId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	query := `
+		INSERT INTO sessions (
+			id,
+			user_id,
+			user_agent,
+			ip,
+			expires_at
+		) VALUES
+			($1, $2, $3, $4, $5)
+		RETURNING
+			created_at,
+			expires_at
+	`
+
+	if err := tx.QueryRow(query, session.Id, session.UserId, session.UserAgent, session.IP,
+		tokens.ExpiresAt).Scan(&session.CreatedAt, &session.ExpiresAt); err != nil {
+		logger.Errorf("failed to insert session: %v", err)
+		return err
+	}
+
+	if err := addTokens(tx, session.Id, tokens); err != nil {
+		logger.Errorf("failed to add session tokens: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RotateTokens - replaces the access token of the session, adds the new refresh token and prolongs the session.
+// The client of the session is updated. Returns the replaced access tokens
+func (r *UserPostgres) RotateTokens(session *domain.Session, tokens *domain.SessionTokens) (revoked []domain.RevokedToken, err error) {
+	logger.Debugf("rotate session tokens: params=[sessionId=%v]", session.Id)
+
+	sessionId := session.Id
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	query := `
+		UPDATE sessions
+		SET
+			expires_at = $1,
+			user_agent = $2,
+			ip = $3
+		WHERE id = $4
+	`
+
+	result, err := tx.Exec(query, tokens.ExpiresAt, session.UserAgent, session.IP, sessionId)
+	if err != nil {
+		logger.Errorf("failed to prolong session: %v", err)
+		return nil, err
+	}
+
+	// The session is revoked in the meantime
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	query = `
+		DELETE FROM tokens
+		WHERE session_id = $1
+		RETURNING
+			token,
+			expires_at
+	`
+
+	if err := tx.Select(&revoked, query, sessionId); err != nil {
+		logger.Errorf("failed to delete access tokens: %v", err)
+		return nil, err
+	}
+
+	if err := addTokens(tx, sessionId, tokens); err != nil {
+		logger.Errorf("failed to add session tokens: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+func addTokens(tx *sqlx.Tx, sessionId string, tokens *domain.SessionTokens) error {
+	query := `
+		INSERT INTO tokens (
+			user_id,
+			token,
+			expires_at,
+			session_id
+		)
+		SELECT
+			user_id,
+			$1,
+			$2,
+			id
+		FROM sessions
+		WHERE id = $3
+	`
+
+	if _, err := tx.Exec(query, tokens.AccessTokenHash, tokens.AccessExpiresAt, sessionId); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO refresh_tokens (
+			token_hash,
+			session_id
+		) VALUES
+			($1, $2)
+	`
+
+	_, err := tx.Exec(query, tokens.RefreshTokenHash, sessionId)
+
+	return err
+}
+
+// UseRefreshToken - marks the refresh token as used and returns its session. A token can be used once,
+// a used token presented again means it's stolen, the session is revoked then with all its tokens
+// and returned with domain.ErrRefreshTokenReused
+func (r *UserPostgres) UseRefreshToken(refreshTokenHash string) (*domain.Session, error) {
+	logger.Debugf("use refresh token")
+
+	query := `
+		UPDATE refresh_tokens r
+		SET used_at = NOW()
+		FROM sessions s
+		WHERE
+			r.token_hash = $1
+			AND r.used_at IS NULL
+			AND s.id = r.session_id
+			AND s.expires_at > NOW()
+		RETURNING
+			s.id,
+			s.user_id,
+			s.user_agent,
+			s.ip,
+			s.created_at,
+			s.expires_at
+	`
+
+	var session domain.Session
+	if err := r.db.Get(&session, query, refreshTokenHash); err == nil {
+		return &session, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf("failed to use refresh token: %v", err)
+		return nil, err
+	}
+
+	query = `
+		WITH reused AS (
+			SELECT session_id
+			FROM refresh_tokens
+			WHERE
+				token_hash = $1
+				AND used_at IS NOT NULL
+		)
+		DELETE FROM sessions
+		WHERE id IN (SELECT session_id FROM reused)
+		RETURNING
+			id,
+			user_id,
+			user_agent,
+			ip,
+			created_at,
+			expires_at
+	`
+
+	if err := r.db.Get(&session, query, refreshTokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+
+		logger.Errorf("failed to revoke session: %v", err)
+		return nil, err
+	}
+
+	logger.Warnf("refresh token reused, session revoked: sessionId=%v", session.Id)
+
+	return &session, domain.ErrRefreshTokenReused
+}
+
+// GetSessions - active sessions of the user, the latest first
+func (r *UserPostgres) GetSessions(userId string) ([]domain.Session, error) {
+	logger.Debugf("get sessions: params=[userId=%v]", userId)
+
+	query := `
+		SELECT
+			id,
+			user_id,
+			user_agent,
+			ip,
+			created_at,
+			expires_at
+		FROM sessions
+		WHERE
+			user_id = $1
+			AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	sessions := make([]domain.Session, 0)
+	if err := r.db.Select(&sessions, query, userId); err != nil {
+		logger.Errorf("failed to get sessions: %v", err)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteUserSession - deletes the session of the user with all its tokens
+func (r *UserPostgres) DeleteUserSession(sessionId, userId string) error {
+	logger.Debugf("delete user session: params=[sessionId=%v userId=%v]", sessionId, userId)
+
+	query := `
+		DELETE FROM sessions
+		WHERE
+			id = $1
+			AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, sessionId, userId)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "22P02" {
+			return domain.ErrSessionNotFound
+		}
+
+		logger.Errorf("failed to delete session: %v", err)
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteUserSessions - deletes all sessions of the user and access tokens issued before sessions,
+// returns ids of the deleted sessions
+func (r *UserPostgres) DeleteUserSessions(userId string) (sessionIds []string, err error) {
+	logger.Debugf("delete user sessions: params=[userId=%v]", userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	query := `
+		DELETE FROM sessions
+		WHERE user_id = $1
+		RETURNING id
+	`
+
+	if err := tx.Select(&sessionIds, query, userId); err != nil {
+		logger.Errorf("failed to delete sessions: %v", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM tokens WHERE user_id = $1`, userId); err != nil {
+		logger.Errorf("failed to delete access tokens: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sessionIds, nil
+}
@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/pkg/logger"
+)
+
+type UploadPostgres struct {
+	db *sqlx.DB
+}
+
+func NewUploadPostgres(db *sqlx.DB) *UploadPostgres {
+	return &UploadPostgres{
+		db: db,
+	}
+}
+
+func (r *UploadPostgres) Create(upload *domain.Upload, userId string) error {
+	logger.Debugf("create upload: params=[userId=%v fileName=%v size=%v]", userId, upload.FileName, upload.Size)
+
+	query := `
+		INSERT INTO uploads (
+			user_id,
+			file_name,
+			size,
+			expires_at
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		RETURNING id, created_at
+	`
+
+	if err := r.db.QueryRowx(query, userId, upload.FileName, upload.Size, upload.ExpiresAt).
+		Scan(&upload.Id, &upload.CreatedAt); err != nil {
+		logger.Errorf("failed to create upload: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetById - returns the upload, uploads are visible to their owners only
+func (r *UploadPostgres) GetById(uploadId, userId string) (*domain.Upload, error) {
+	logger.Debugf("get upload: params=[uploadId=%v userId=%v]", uploadId, userId)
+
+	query := `
+		SELECT
+			id,
+			file_name,
+			size,
+			received,
+			completing AND expires_at > NOW() AS completing,
+			created_at,
+			expires_at
+		FROM uploads
+		WHERE id = $1 AND user_id = $2
+	`
+
+	var upload domain.Upload
+	if err := r.db.Get(&upload, query, uploadId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUploadNotFound
+		}
+
+		logger.Errorf("failed to get upload: %v", err)
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// AddChunk - appends the stored chunk to the upload and prolongs it, returns the offset after the chunk.
+// The chunk must start where the upload ends
+func (r *UploadPostgres) AddChunk(uploadId, userId string, chunk *domain.UploadChunk, expiresAt time.Time) (offset int64, err error) {
+	logger.Debugf("add upload chunk: params=[uploadId=%v userId=%v start=%v size=%v]", uploadId, userId, chunk.Start, chunk.Size)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	upload, err := lockUpload(tx, uploadId, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if upload.Completing || upload.Offset != chunk.Start {
+		return 0, domain.ErrUploadOffsetMismatch
+	}
+
+	if upload.Offset+chunk.Size > upload.Size {
+		return 0, domain.ErrChunkIsTooLarge
+	}
+
+	query := `
+		INSERT INTO upload_chunks (
+			upload_id,
+			start,
+			size,
+			blob_key
+		) VALUES (
+			$1, $2, $3, $4
+		)
+	`
+
+	if _, err := tx.Exec(query, uploadId, chunk.Start, chunk.Size, chunk.BlobKey); err != nil {
+		logger.Errorf("failed to insert upload chunk: uploadId=%v: %v", uploadId, err)
+		return 0, err
+	}
+
+	query = `
+		UPDATE uploads
+		SET
+			received = received + $1,
+			expires_at = $2
+		WHERE id = $3
+		RETURNING received
+	`
+
+	if err := tx.Get(&offset, query, chunk.Size, expiresAt, uploadId); err != nil {
+		logger.Errorf("failed to update upload: uploadId=%v: %v", uploadId, err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return offset, nil
+}
+
+// StartCompleting - marks the received upload as being completed until it expires, so chunks can't be added,
+// the upload isn't deleted and isn't completed twice. Returns the upload and its chunks in order
+func (r *UploadPostgres) StartCompleting(uploadId, userId string, expiresAt time.Time) (
+	upload *domain.Upload, chunks []domain.UploadChunk, err error) {
+	logger.Debugf("start completing upload: params=[uploadId=%v userId=%v]", uploadId, userId)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	upload, err = lockUpload(tx, uploadId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The completion interrupted by a crash is taken over once the upload expires
+	if upload.Completing {
+		return nil, nil, domain.ErrUploadIsCompleting
+	}
+
+	if upload.Offset != upload.Size {
+		return nil, nil, domain.ErrUploadIsIncomplete
+	}
+
+	// The upload is prolonged, so it isn't deleted as expired while its file is copied
+	query := `
+		UPDATE uploads
+		SET
+			completing = TRUE,
+			expires_at = $1
+		WHERE id = $2
+	`
+
+	if _, err := tx.Exec(query, expiresAt, uploadId); err != nil {
+		logger.Errorf("failed to update upload: uploadId=%v: %v", uploadId, err)
+		return nil, nil, err
+	}
+
+	query = `
+		SELECT
+			start,
+			size,
+			blob_key
+		FROM upload_chunks
+		WHERE upload_id = $1
+		ORDER BY start
+	`
+
+	chunks = make([]domain.UploadChunk, 0)
+	if err := tx.Select(&chunks, query, uploadId); err != nil {
+		logger.Errorf("failed to get upload chunks: uploadId=%v: %v", uploadId, err)
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	upload.Completing = true
+	upload.ExpiresAt = expiresAt
+
+	return upload, chunks, nil
+}
+
+// CancelCompleting - returns the upload whose document wasn't created, so it can be completed again
+func (r *UploadPostgres) CancelCompleting(uploadId string) error {
+	logger.Debugf("cancel completing upload: params=[uploadId=%v]", uploadId)
+
+	query := `
+		UPDATE uploads
+		SET completing = FALSE
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, uploadId); err != nil {
+		logger.Errorf("failed to update upload: uploadId=%v: %v", uploadId, err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete - deletes the upload, returns keys of its chunks to delete from the storage.
+// The upload being completed can't be deleted, its chunks are being read
+func (r *UploadPostgres) Delete(uploadId, userId string) (blobKeys []string, err error) {
+	logger.Debugf("delete upload: params=[uploadId=%v userId=%v]", uploadId, userId)
+
+	return r.delete(uploadId, userId, false)
+}
+
+// FinishCompleting - deletes the upload whose document was created, returns keys of its chunks
+func (r *UploadPostgres) FinishCompleting(uploadId, userId string) (blobKeys []string, err error) {
+	logger.Debugf("finish completing upload: params=[uploadId=%v userId=%v]", uploadId, userId)
+
+	return r.delete(uploadId, userId, true)
+}
+
+func (r *UploadPostgres) delete(uploadId, userId string, completed bool) (blobKeys []string, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) && err == nil {
+			err = fmt.Errorf("failed to rollback transaction: %w", rerr)
+		}
+	}()
+
+	upload, err := lockUpload(tx, uploadId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if upload.Completing && !completed {
+		return nil, domain.ErrUploadIsCompleting
+	}
+
+	query := `
+		SELECT blob_key
+		FROM upload_chunks
+		WHERE upload_id = $1
+	`
+
+	blobKeys = make([]string, 0)
+	if err := tx.Select(&blobKeys, query, uploadId); err != nil {
+		logger.Errorf("failed to get upload chunks: uploadId=%v: %v", uploadId, err)
+		return nil, err
+	}
+
+	query = `
+		DELETE FROM uploads
+		WHERE id = $1
+	`
+
+	if _, err := tx.Exec(query, uploadId); err != nil {
+		logger.Errorf("failed to delete upload: uploadId=%v: %v", uploadId, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return blobKeys, nil
+}
+
+// DeleteExpired - deletes up to limit expired uploads, returns their number and keys of their chunks
+func (r *UploadPostgres) DeleteExpired(limit int) (uploads int, blobKeys []string, err error) {
+	logger.Debugf("delete expired uploads: params=[limit=%v]", limit)
+
+	// Uploads being completed are prolonged, they expire only if the completion was interrupted.
+	// The select sees chunks as they were before the cascade delete
+	query := `
+		WITH expired AS (
+			DELETE FROM uploads
+			WHERE id IN (
+				SELECT id
+				FROM uploads
+				WHERE expires_at < NOW()
+				ORDER BY expires_at
+				LIMIT $1
+			)
+			RETURNING id
+		)
+		SELECT
+			e.id,
+			c.blob_key
+		FROM expired e
+		LEFT JOIN upload_chunks c ON e.id = c.upload_id
+	`
+
+	var rows []struct {
+		Id      string         `db:"id"`
+		BlobKey sql.NullString `db:"blob_key"`
+	}
+	if err := r.db.Select(&rows, query, limit); err != nil {
+		logger.Errorf("failed to delete expired uploads: %v", err)
+		return 0, nil, err
+	}
+
+	ids := make(map[string]struct{})
+	blobKeys = make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids[row.Id] = struct{}{}
+		if row.BlobKey.Valid {
+			blobKeys = append(blobKeys, row.BlobKey.String)
+		}
+	}
+
+	return len(ids), blobKeys, nil
+}
+
+// lockUpload - locks the upload of the user until the end of the transaction
+func lockUpload(tx *sqlx.Tx, uploadId, userId string) (*domain.Upload, error) {
+	query := `
+		SELECT
+			id,
+			file_name,
+			size,
+			received,
+			completing AND expires_at > NOW() AS completing,
+			created_at,
+			expires_at
+		FROM uploads
+		WHERE id = $1 AND user_id = $2
+		FOR UPDATE
+	`
+
+	var upload domain.Upload
+	if err := tx.Get(&upload, query, uploadId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUploadNotFound
+		}
+
+		logger.Errorf("failed to get upload: %v", err)
+		return nil, err
+	}
+
+	return &upload, nil
+}
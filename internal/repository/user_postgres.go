@@ -45,50 +45,53 @@ func (r *UserPostgres) Create(login, password string) error {
 	return nil
 }
 
-func (r *UserPostgres) GetByCredentials(login, password string) (string, error) {
-	logger.Debugf("get user by credentials: params=[login=%v password=%v]", login, password)
+// GetCredentials - returns the user and the password hash to verify the password against
+func (r *UserPostgres) GetCredentials(login string) (userId, passwordHash string, err error) {
+	logger.Debugf("get user credentials: params=[login=%v]", login)
 
 	query := `
 		SELECT 
-			id
+			id,
+			password_hash
 		FROM users 
-		WHERE login = $1 AND password_hash = $2`
+		WHERE login = $1`
 
-	var id string
-	if err := r.db.Get(&id, query, login, password); err != nil {
+	var user struct {
+		Id           string `db:"id"`
+		PasswordHash string `db:"password_hash"`
+	}
+	if err := r.db.Get(&user, query, login); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", domain.ErrUserNotFound
+			return "", "", domain.ErrUserNotFound
 		}
 
-		logger.Errorf("failed to get user by credentials: %v", err)
-		return "", err
+		logger.Errorf("failed to get user credentials: %v", err)
+		return "", "", err
 	}
 
-	return id, nil
+	return user.Id, user.PasswordHash, nil
 }
 
-func (r *UserPostgres) AddSession(session domain.Session) error {
-	logger.Debugf("add session: params[session=%v]", session)
+func (r *UserPostgres) UpdatePassword(userId, passwordHash string) error {
+	logger.Debugf("update password: params=[userId=%v]", userId)
 
 	query := `
-		INSERT INTO tokens (
-			user_id,
-			token,
-			expires_at
-		) VALUES
-			($1, $2, $3)
+		UPDATE users
+		SET password_hash = $1
+		WHERE id = $2
 	`
 
-	if _, err := r.db.Exec(query, session.UserId, session.AccessToken, session.ExpiresAt); err != nil {
-		logger.Errorf("failed to insert token: %v", err)
+	if _, err := r.db.Exec(query, passwordHash, userId); err != nil {
+		logger.Errorf("failed to update password: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-func (r *UserPostgres) GetUserIdBySession(session string) (userId string, err error) {
-	logger.Debugf("get userId by session: params[session=%v]", session)
+// GetUserIdBySession - returns the owner of the access token by the token hash
+func (r *UserPostgres) GetUserIdBySession(tokenHash string) (userId string, err error) {
+	logger.Debugf("get userId by session")
 
 	query := `
 		SELECT 
@@ -99,7 +102,7 @@ func (r *UserPostgres) GetUserIdBySession(session string) (userId string, err er
 			AND expires_at > now()
 	`
 
-	if err = r.db.Get(&userId, query, session); err != nil {
+	if err = r.db.Get(&userId, query, tokenHash); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", domain.ErrUserNotFound
 		}
@@ -110,18 +113,32 @@ func (r *UserPostgres) GetUserIdBySession(session string) (userId string, err er
 	return userId, nil
 }
 
-func (r *UserPostgres) DeleteSession(session string) error {
-	logger.Debugf("delete session: params[session=%v]", session)
+// DeleteSession - deletes the session the access token of the user is issued to with all its tokens,
+// the token is found by its hash
+func (r *UserPostgres) DeleteSession(tokenHash, userId string) error {
+	logger.Debugf("delete session: params[userId=%v]", userId)
 	query := `
-	  DELETE FROM tokens
-	  WHERE token = $1
+	  WITH token AS (
+	  	DELETE FROM tokens
+	  	WHERE token = $1 AND user_id = $2
+	  	RETURNING session_id
+	  ), deleted AS (
+	  	DELETE FROM sessions
+	  	WHERE id IN (SELECT session_id FROM token)
+	  )
+	  SELECT COUNT(*) FROM token
 	`
 
-	if _, err := r.db.Exec(query, session); err != nil {
+	var deleted int
+	if err := r.db.Get(&deleted, query, tokenHash, userId); err != nil {
 		logger.Errorf("failed to delete session: %v", err)
 		return err
 	}
 
+	if deleted == 0 {
+		return domain.ErrSessionNotFound
+	}
+
 	return nil
 }
 
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/config"
+	"github.com/sixojke/test-astral/internal/repository"
+	"github.com/sixojke/test-astral/pkg/extract"
+	"github.com/sixojke/test-astral/pkg/logger"
+	"github.com/sixojke/test-astral/pkg/storage"
+)
+
+// ContentExtractor - extracts text of uploaded files in the background, so documents
+// can be found by their contents. Documents missed by the queue are picked up by the periodic scan
+type ContentExtractor struct {
+	repo   repository.Content
+	store  storage.BlobStore
+	config config.DocumentsExtract
+
+	queue  chan string
+	mu     sync.Mutex
+	queued map[string]bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewContentExtractor(repo repository.Content, store storage.BlobStore, config config.DocumentsExtract) *ContentExtractor {
+	return &ContentExtractor{
+		repo:   repo,
+		store:  store,
+		config: config,
+		queue:  make(chan string, max(config.QueueSize, 1)),
+		queued: make(map[string]bool),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start - starts the workers and the scan of pending documents
+func (e *ContentExtractor) Start() {
+	if e.config.Workers <= 0 {
+		return
+	}
+
+	for i := 0; i < e.config.Workers; i++ {
+		e.wg.Add(1)
+		go e.work()
+	}
+
+	e.wg.Add(1)
+	go e.scan()
+}
+
+// Stop - waits for the files being processed, the rest is left for the next start
+func (e *ContentExtractor) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+// Enqueue - schedules the text extraction of the document file, never blocks:
+// if the queue is full the document is left for the scan
+func (e *ContentExtractor) Enqueue(documentId string) {
+	if e.config.Workers <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.queued[documentId] {
+		return
+	}
+
+	select {
+	case e.queue <- documentId:
+		e.queued[documentId] = true
+	default:
+		logger.Debugf("content queue is full: documentId=%v", documentId)
+	}
+}
+
+func (e *ContentExtractor) work() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case documentId := <-e.queue:
+			e.process(documentId)
+
+			e.mu.Lock()
+			delete(e.queued, documentId)
+			e.mu.Unlock()
+		}
+	}
+}
+
+func (e *ContentExtractor) scan() {
+	defer e.wg.Done()
+
+	e.enqueuePending()
+
+	if e.config.ScanInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(e.config.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.enqueuePending()
+		}
+	}
+}
+
+func (e *ContentExtractor) enqueuePending() {
+	ids, err := e.repo.GetPending(cap(e.queue))
+	if err != nil {
+		return
+	}
+
+	for _, id := range ids {
+		e.Enqueue(id)
+	}
+}
+
+func (e *ContentExtractor) process(documentId string) {
+	content, err := e.repo.GetById(documentId)
+	if err != nil {
+		// Already extracted, deleted or the file has been removed from the document
+		return
+	}
+
+	text, err := e.text(content)
+	if err != nil {
+		// The document stays pending and is retried by the scan
+		logger.Errorf("failed to extract content: documentId=%v: %v", documentId, err)
+		return
+	}
+
+	if err := e.repo.Set(documentId, content.FilePath, text); err != nil {
+		return
+	}
+
+	logger.Debugf("content extracted: documentId=%v hasText=%v", documentId, text != nil)
+}
+
+// text - returns text of the document file, nil if the file has no text that can be extracted.
+// Returns an error only if the file couldn't be read
+func (e *ContentExtractor) text(content *domain.DocumentContent) (*string, error) {
+	if !extract.Supported(content.Mime, content.Name) {
+		return nil, nil
+	}
+
+	info, err := e.store.Stat(content.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logger.Errorf("content file not found: key=%v", content.FilePath)
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if e.config.MaxFileSizeMb > 0 && info.Size > e.config.MaxFileSizeMb<<20 {
+		logger.Debugf("content file is too large: key=%v size=%v", content.FilePath, info.Size)
+		return nil, nil
+	}
+
+	file, err := e.store.Get(content.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := extract.Text(bytes.NewReader(data), int64(len(data)), content.Mime, content.Name)
+	if err != nil {
+		logger.Warnf("failed to extract text: key=%v mime=%v: %v", content.FilePath, content.Mime, err)
+		return nil, nil
+	}
+
+	if text == "" {
+		return nil, nil
+	}
+
+	return &text, nil
+}
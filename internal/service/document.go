@@ -1,31 +1,175 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"os"
+	"fmt"
+	"io"
+	"time"
 
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/config"
 	"github.com/sixojke/test-astral/internal/repository"
 	"github.com/sixojke/test-astral/pkg/logger"
+	"github.com/sixojke/test-astral/pkg/storage"
 )
 
+const shareTokenLength = 32
+
+// ContentIndexer - schedules the text extraction of document files for the search
+type ContentIndexer interface {
+	Enqueue(documentId string)
+}
+
+// SchemaValidator - checks JSON data of documents against their schemas
+type SchemaValidator interface {
+	Validate(name, data string) error
+}
+
 type DocumentService struct {
-	repo     repository.Document
-	repoUser repository.User
+	repo        repository.Document
+	repoUser    repository.User
+	blobs       repository.Blob
+	store       storage.BlobStore
+	indexer     ContentIndexer
+	validator   SchemaValidator
+	linksConfig config.DocumentsLinks
+	mimeConfig  config.DocumentsMime
 }
 
-func NewDocumentService(repo repository.Document, repoUser repository.User) *DocumentService {
+func NewDocumentService(repo repository.Document, repoUser repository.User, blobs repository.Blob,
+	store storage.BlobStore, indexer ContentIndexer, validator SchemaValidator, linksConfig config.DocumentsLinks,
+	mimeConfig config.DocumentsMime) *DocumentService {
 	return &DocumentService{
-		repo:     repo,
-		repoUser: repoUser,
+		repo:        repo,
+		repoUser:    repoUser,
+		blobs:       blobs,
+		store:       store,
+		indexer:     indexer,
+		validator:   validator,
+		linksConfig: linksConfig,
+		mimeConfig:  mimeConfig,
+	}
+}
+
+func (s *DocumentService) Create(document *domain.Document, file *domain.File, userId string) (unknownGrants []string, err error) {
+	if document.Type != "" {
+		if err := s.validator.Validate(document.Type, document.DocumentData); err != nil {
+			return nil, err
+		}
+	}
+
+	if file == nil {
+		return s.repo.Create(document, userId)
+	}
+
+	detected, err := detectMime(file.Content)
+	if err != nil {
+		logger.Errorf("failed to detect file type: %v", err)
+		return nil, err
+	}
+
+	document.Mime, document.MimeMismatch = resolveMime(document.Mime, detected)
+	if err := checkMime(s.mimeConfig, document.Mime); err != nil {
+		return nil, err
+	}
+
+	key, err := s.putFile(file)
+	if err != nil {
+		return nil, err
+	}
+	// The document holds its own reference after it's saved
+	defer s.releaseFile(key)
+
+	document.FilePath = key
+
+	unknownGrants, err = s.repo.Create(document, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.indexer.Enqueue(document.Id)
+
+	return unknownGrants, nil
+}
+
+// putFile - stores the file under the key derived from its SHA-256 digest, identical files share one blob.
+// The blob is acquired before it's stored, so it can't be deleted while the document is saved.
+// The reference must be released with releaseFile
+func (s *DocumentService) putFile(file *domain.File) (key string, err error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file.Content); err != nil {
+		logger.Errorf("failed to hash file: %v", err)
+		return "", err
+	}
+
+	if _, err := file.Content.Seek(0, io.SeekStart); err != nil {
+		logger.Errorf("failed to rewind file: %v", err)
+		return "", err
+	}
+
+	key = blobKey(hex.EncodeToString(hash.Sum(nil)))
+
+	if err := s.blobs.Acquire(key); err != nil {
+		return "", err
+	}
+
+	if _, err := s.store.Stat(key); err == nil {
+		return key, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		logger.Errorf("failed to stat file: %v", err)
+		s.releaseFile(key)
+		return "", err
+	}
+
+	if err := s.store.Put(key, file.Content, file.Size); err != nil {
+		logger.Errorf("failed to save file: %v", err)
+		s.releaseFile(key)
+		return "", err
+	}
+
+	return key, nil
+}
+
+// releaseFile - releases the reference acquired by putFile, the file is deleted if nothing else references it
+func (s *DocumentService) releaseFile(key string) {
+	orphaned, err := s.blobs.Release(key)
+	if err != nil {
+		return
+	}
+
+	if orphaned {
+		s.deleteFile(key)
 	}
 }
 
-func (s *DocumentService) Create(document *domain.Document, userId string) error {
-	return s.repo.Create(document, userId)
+// deleteFile - deletes the released file from the storage unless it's acquired again in the meantime
+func (s *DocumentService) deleteFile(key string) {
+	if _, err := s.blobs.Delete(key, blobDeleter(s.store)); err != nil {
+		logger.Errorf("failed to delete file: key=%v: %v", key, err)
+	}
+}
+
+// blobDeleter - deletes files of the storage, a missing file counts as deleted
+func blobDeleter(store storage.BlobStore) func(key string) error {
+	return func(key string) error {
+		if err := store.Delete(key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func blobKey(digest string) string {
+	return fmt.Sprintf("sha256/%v/%v", digest[:2], digest)
 }
 
-func (s *DocumentService) GetByUser(userLogin, currentUserId string, params *domain.FilterParams) (*[]domain.Document, error) {
+func (s *DocumentService) GetByUser(userLogin, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
 	logger.Debugf("login=%v", userLogin)
 	if userLogin == "" {
 		return s.repo.GetCurrentUserDocuments(currentUserId, params)
@@ -40,16 +184,149 @@ func (s *DocumentService) GetByUser(userLogin, currentUserId string, params *dom
 	return s.repo.GetOtherUserDocuments(userId, currentUserId, params)
 }
 
+func (s *DocumentService) GetByFolder(folderId, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error) {
+	return s.repo.GetFolderDocuments(folderId, currentUserId, params)
+}
+
+func (s *DocumentService) Search(query, userId string, params *domain.FilterParams) ([]domain.SearchResult, error) {
+	return s.repo.Search(query, userId, params)
+}
+
 func (s *DocumentService) GetById(documentId, userId string) (*domain.Document, error) {
 	return s.repo.GetById(documentId, userId)
 }
 
-func (s *DocumentService) CheckById(documentId, userId string) (bool, error) {
-	return s.repo.CheckById(documentId, userId)
+func (s *DocumentService) OpenFile(filePath string) (io.ReadCloser, *storage.BlobInfo, error) {
+	info, err := s.StatFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.store.Get(filePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, domain.ErrFileIsDamagedOrNotFound
+		}
+
+		logger.Errorf("failed to get file: %v", err)
+		return nil, nil, err
+	}
+
+	return file, info, nil
+}
+
+// OpenFileRange - opens length bytes of the file from the offset, the range must be inside the file
+func (s *DocumentService) OpenFileRange(filePath string, offset, length int64) (io.ReadCloser, error) {
+	file, err := s.store.GetRange(filePath, offset, length)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, domain.ErrFileIsDamagedOrNotFound
+		}
+
+		logger.Errorf("failed to get file range: %v", err)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (s *DocumentService) StatFile(filePath string) (*storage.BlobInfo, error) {
+	info, err := s.store.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, domain.ErrFileIsDamagedOrNotFound
+		}
+
+		logger.Errorf("failed to stat file: %v", err)
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (s *DocumentService) Update(documentId, userId string, update *domain.DocumentUpdate) (unknownGrants []string, err error) {
+	if update.Mime != nil {
+		if err := s.resolveUpdateMime(documentId, userId, update); err != nil {
+			return nil, err
+		}
+	}
+
+	// The data is validated by the repository with the schema and data locked for the update
+	return s.repo.Update(documentId, userId, update, s.validator.Validate)
+}
+
+// resolveUpdateMime - checks the new mime of a file against its content, the mime of other documents is kept as passed
+func (s *DocumentService) resolveUpdateMime(documentId, userId string, update *domain.DocumentUpdate) error {
+	document, err := s.repo.GetById(documentId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !document.IsFile || document.FilePath == "" {
+		return nil
+	}
+
+	// Only the first bytes are read, the rest isn't transferred
+	file, _, err := s.OpenFile(document.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	detected, err := mimetype.DetectReader(file)
+	if err != nil {
+		logger.Errorf("failed to detect file type: %v", err)
+		return err
+	}
+
+	mime, mismatch := resolveMime(*update.Mime, detected)
+	if err := checkMime(s.mimeConfig, mime); err != nil {
+		return err
+	}
+
+	update.Mime, update.MimeMismatch = &mime, &mismatch
+
+	return nil
+}
+
+func (s *DocumentService) UpdateFile(documentId, userId string, file *domain.File, mime string) error {
+	detected, err := detectMime(file.Content)
+	if err != nil {
+		logger.Errorf("failed to detect file type: %v", err)
+		return err
+	}
+
+	mime, mismatch := resolveMime(mime, detected)
+	if err := checkMime(s.mimeConfig, mime); err != nil {
+		return err
+	}
+
+	key, err := s.putFile(file)
+	if err != nil {
+		return err
+	}
+	defer s.releaseFile(key)
+
+	oldKey, err := s.repo.UpdateFile(documentId, userId, key, mime, mismatch)
+	if err != nil {
+		if !errors.Is(err, domain.ErrDocumentNotFound) && !errors.Is(err, domain.ErrDocumentIsNotFile) {
+			logger.Errorf("failed to update document file: %v", err)
+		}
+
+		return err
+	}
+
+	if oldKey != "" {
+		s.deleteFile(oldKey)
+	}
+
+	s.indexer.Enqueue(documentId)
+
+	return nil
 }
 
 func (s *DocumentService) Delete(documentId, userId string) error {
-	filePath, err := s.repo.Delete(documentId, userId)
+	filePaths, err := s.repo.Delete(documentId, userId)
 	if err != nil {
 		if errors.Is(err, domain.ErrDocumentNotFound) {
 			return err
@@ -59,9 +336,115 @@ func (s *DocumentService) Delete(documentId, userId string) error {
 		return err
 	}
 
-	if err = os.Remove(filePath); err != nil {
-		logger.Errorf("failed to delete file: %v", err)
+	for _, filePath := range filePaths {
+		s.deleteFile(filePath)
 	}
 
 	return nil
 }
+
+func (s *DocumentService) GetVersions(documentId, userId string) (*[]domain.DocumentVersion, error) {
+	return s.repo.GetVersions(documentId, userId)
+}
+
+func (s *DocumentService) GetVersion(documentId, userId string, version int) (*domain.DocumentVersion, error) {
+	return s.repo.GetVersion(documentId, userId, version)
+}
+
+func (s *DocumentService) RestoreVersion(documentId, userId string, version int) error {
+	if err := s.validateVersion(documentId, userId, version); err != nil {
+		return err
+	}
+
+	if err := s.repo.RestoreVersion(documentId, userId, version); err != nil {
+		return err
+	}
+
+	s.indexer.Enqueue(documentId)
+
+	return nil
+}
+
+// validateVersion - checks the data of the version against the current schema of the document,
+// the schema could change since the version was saved
+func (s *DocumentService) validateVersion(documentId, userId string, version int) error {
+	document, err := s.repo.GetById(documentId, userId)
+	if err != nil {
+		return err
+	}
+
+	if document.Type == "" {
+		return nil
+	}
+
+	v, err := s.repo.GetVersion(documentId, userId, version)
+	if err != nil {
+		return err
+	}
+
+	return s.validator.Validate(document.Type, v.DocumentData)
+}
+
+func (s *DocumentService) GetGrants(documentId, userId string) ([]domain.Grant, error) {
+	return s.repo.GetGrants(documentId, userId)
+}
+
+func (s *DocumentService) AddGrants(documentId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	return s.repo.AddGrants(documentId, userId, grants)
+}
+
+func (s *DocumentService) DeleteGrants(documentId, userId string, subjects []string) (unknown []string, err error) {
+	return s.repo.DeleteGrants(documentId, userId, subjects)
+}
+
+// CreateShareLink - creates a share link of the document, zero ttl means the default lifetime
+func (s *DocumentService) CreateShareLink(documentId, userId string, ttl time.Duration, maxDownloads *int) (*domain.ShareLink, error) {
+	if ttl == 0 {
+		ttl = s.linksConfig.DefaultTTL
+	}
+
+	if ttl < 0 || (s.linksConfig.MaxTTL > 0 && ttl > s.linksConfig.MaxTTL) {
+		return nil, domain.ErrInvalidExpiration
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		logger.Errorf("failed to generate share token: %v", err)
+		return nil, err
+	}
+
+	// Only the hash is stored, the token is returned once
+	link := &domain.ShareLink{
+		Token:        token,
+		TokenHash:    hashToken(token),
+		MaxDownloads: maxDownloads,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	if err := s.repo.CreateShareLink(documentId, userId, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *DocumentService) GetShareLinks(documentId, userId string) ([]domain.ShareLink, error) {
+	return s.repo.GetShareLinks(documentId, userId)
+}
+
+func (s *DocumentService) DeleteShareLink(documentId, userId, linkId string) error {
+	return s.repo.DeleteShareLink(documentId, userId, linkId)
+}
+
+func (s *DocumentService) GetByShareToken(token string) (*domain.Document, error) {
+	return s.repo.UseShareLink(hashToken(token))
+}
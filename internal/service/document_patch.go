@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/sixojke/test-astral/domain"
+)
+
+// PatchData - applies the patch to the JSON data of the document, returns the document with the new data.
+// The patch and the If-Match check see the locked document, so nothing changes between them and the save.
+// Fails with domain.ErrDocumentChanged if the document doesn't match If-Match
+func (s *DocumentService) PatchData(documentId, userId string, patch *domain.DataPatch) (*domain.Document, error) {
+	_, err := s.repo.UpdateData(documentId, userId, func(document *domain.Document) (string, error) {
+		if patch.IfMatch != "" && !domain.MatchETag(patch.IfMatch, document.ETag()) {
+			return "", domain.ErrDocumentChanged
+		}
+
+		data, err := applyPatch(patch, document.DocumentData)
+		if err != nil {
+			return "", err
+		}
+
+		if document.Type != "" {
+			if err := s.validator.Validate(document.Type, data); err != nil {
+				return "", err
+			}
+		}
+
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The document is read again for the ETag of its new state
+	return s.repo.GetById(documentId, userId)
+}
+
+// applyPatch - returns the data after the patch, empty data is JSON null
+func applyPatch(patch *domain.DataPatch, data string) (string, error) {
+	if data == "" {
+		data = "null"
+	}
+
+	var patched []byte
+	switch patch.Type {
+	case domain.PatchJSON:
+		operations, err := jsonpatch.DecodePatch(patch.Patch)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", domain.ErrInvalidPatch, err)
+		}
+
+		if patched, err = operations.Apply([]byte(data)); err != nil {
+			return "", fmt.Errorf("%w: %v", domain.ErrPatchConflict, err)
+		}
+	case domain.PatchMerge:
+		if !json.Valid(patch.Patch) {
+			return "", domain.ErrInvalidPatch
+		}
+
+		// A merge patch turns anything but an object into an object, null included.
+		// The data comes from JSONB, so it isn't indented
+		if !strings.HasPrefix(data, "{") {
+			data = "{}"
+		}
+
+		var err error
+		if patched, err = jsonpatch.MergePatch([]byte(data), patch.Patch); err != nil {
+			return "", fmt.Errorf("%w: %v", domain.ErrPatchConflict, err)
+		}
+	default:
+		return "", domain.ErrUnsupportedPatch
+	}
+
+	if string(patched) == "null" {
+		return "", nil
+	}
+
+	return string(patched), nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/repository"
+)
+
+type FolderService struct {
+	repo repository.Folder
+}
+
+func NewFolderService(repo repository.Folder) *FolderService {
+	return &FolderService{
+		repo: repo,
+	}
+}
+
+func (s *FolderService) Create(folder *domain.Folder, userId string) error {
+	return s.repo.Create(folder, userId)
+}
+
+func (s *FolderService) GetByUser(userId string) ([]domain.Folder, error) {
+	return s.repo.GetByUser(userId)
+}
+
+func (s *FolderService) GetById(folderId, userId string) (*domain.Folder, error) {
+	return s.repo.GetById(folderId, userId)
+}
+
+func (s *FolderService) GetChildren(folderId, userId string) ([]domain.Folder, error) {
+	return s.repo.GetChildren(folderId, userId)
+}
+
+func (s *FolderService) Update(folderId, userId string, update *domain.FolderUpdate) error {
+	return s.repo.Update(folderId, userId, update)
+}
+
+func (s *FolderService) Delete(folderId, userId string) error {
+	return s.repo.Delete(folderId, userId)
+}
+
+func (s *FolderService) GetGrants(folderId, userId string) ([]domain.Grant, error) {
+	return s.repo.GetGrants(folderId, userId)
+}
+
+func (s *FolderService) AddGrants(folderId, userId string, grants []domain.Grant) (unknown []string, err error) {
+	return s.repo.AddGrants(folderId, userId, grants)
+}
+
+func (s *FolderService) DeleteGrants(folderId, userId string, subjects []string) (unknown []string, err error) {
+	return s.repo.DeleteGrants(folderId, userId, subjects)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/repository"
+)
+
+type GroupService struct {
+	repo repository.Group
+}
+
+func NewGroupService(repo repository.Group) *GroupService {
+	return &GroupService{
+		repo: repo,
+	}
+}
+
+func (s *GroupService) Create(group *domain.Group, ownerId string, members []string) (unknown []string, err error) {
+	return s.repo.Create(group, ownerId, members)
+}
+
+func (s *GroupService) GetByUser(userId string) ([]domain.Group, error) {
+	return s.repo.GetByUser(userId)
+}
+
+func (s *GroupService) GetById(groupId, userId string) (*domain.Group, error) {
+	return s.repo.GetById(groupId, userId)
+}
+
+func (s *GroupService) Rename(groupId, userId, name string) error {
+	return s.repo.Rename(groupId, userId, name)
+}
+
+func (s *GroupService) Delete(groupId, userId string) error {
+	return s.repo.Delete(groupId, userId)
+}
+
+func (s *GroupService) AddMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	return s.repo.AddMembers(groupId, userId, logins)
+}
+
+func (s *GroupService) DeleteMembers(groupId, userId string, logins []string) (unknown []string, err error) {
+	return s.repo.DeleteMembers(groupId, userId, logins)
+}
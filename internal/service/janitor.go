@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sixojke/test-astral/internal/config"
+	"github.com/sixojke/test-astral/internal/repository"
+	"github.com/sixojke/test-astral/pkg/logger"
+	"github.com/sixojke/test-astral/pkg/storage"
+)
+
+const (
+	// expiredSessionsBatch - sessions or tokens deleted by one query of the janitor
+	expiredSessionsBatch = 1000
+	// storedFilesBatch - files of the storage checked for references by one query
+	storedFilesBatch = 500
+
+	orphansDelete = "delete"
+)
+
+var errJanitorStopped = errors.New("janitor stopped")
+
+// Janitor - purges expired sessions and tokens and reconciles the storage with the documents in the background.
+// Files no document, version or upload references are reported or deleted
+type Janitor struct {
+	repo            repository.Janitor
+	blobs           repository.Blob
+	store           storage.BlobStore
+	config          config.Janitor
+	refreshTokenTTL time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewJanitor(repo repository.Janitor, blobs repository.Blob, store storage.BlobStore, config config.Janitor,
+	refreshTokenTTL time.Duration) *Janitor {
+	return &Janitor{
+		repo:            repo,
+		blobs:           blobs,
+		store:           store,
+		config:          config,
+		refreshTokenTTL: refreshTokenTTL,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start - starts the periodic cleanup, zero interval disables it
+func (j *Janitor) Start() {
+	if j.config.Interval <= 0 {
+		return
+	}
+
+	j.wg.Add(1)
+	go j.run()
+}
+
+// Stop - interrupts the cleanup in progress and waits for it
+func (j *Janitor) Stop() {
+	close(j.stop)
+	j.wg.Wait()
+}
+
+func (j *Janitor) run() {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			j.purgeSessions()
+			j.deleteReleased()
+			j.reconcileStorage()
+		}
+	}
+}
+
+func (j *Janitor) stopped() bool {
+	select {
+	case <-j.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// purgeSessions - deletes expired sessions with their tokens, expired access tokens and refresh tokens
+// used longer than their lifetime ago. Sessions refreshed in time never expire and keep their used tokens
+func (j *Janitor) purgeSessions() {
+	sessions := j.purge(j.repo.DeleteExpiredSessions)
+	tokens := j.purge(j.repo.DeleteExpiredTokens)
+
+	usedBefore := time.Now().Add(-j.refreshTokenTTL)
+	refreshTokens := j.purge(func(limit int) (int64, error) {
+		return j.repo.DeleteUsedRefreshTokens(usedBefore, limit)
+	})
+
+	if sessions > 0 || tokens > 0 || refreshTokens > 0 {
+		logger.Infof("purged expired sessions: sessions=%v tokens=%v refresh_tokens=%v", sessions, tokens, refreshTokens)
+	}
+}
+
+// purge - deletes in batches until a batch isn't full, returns the number of deleted rows
+func (j *Janitor) purge(deleteExpired func(limit int) (int64, error)) int64 {
+	var total int64
+	for !j.stopped() {
+		deleted, err := deleteExpired(expiredSessionsBatch)
+		if err != nil {
+			return total
+		}
+
+		total += deleted
+
+		if deleted < expiredSessionsBatch {
+			break
+		}
+	}
+
+	return total
+}
+
+// deleteReleased - deletes files of blobs whose last reference was released, but the file wasn't deleted
+func (j *Janitor) deleteReleased() {
+	var deleted int
+	after := ""
+	for !j.stopped() {
+		keys, err := j.repo.GetReleasedBlobs(after, storedFilesBatch)
+		if err != nil {
+			return
+		}
+
+		for _, key := range keys {
+			if ok, err := j.blobs.Delete(key, blobDeleter(j.store)); err != nil {
+				logger.Errorf("failed to delete released file: key=%v: %v", key, err)
+			} else if ok {
+				deleted++
+			}
+		}
+
+		if len(keys) < storedFilesBatch {
+			break
+		}
+
+		after = keys[len(keys)-1]
+	}
+
+	if deleted > 0 {
+		logger.Infof("deleted released files: count=%v", deleted)
+	}
+}
+
+// reconcileStorage - looks for files of the storage that nothing references. Files younger than
+// the min age are skipped, a request may have stored the file and not yet saved the document
+func (j *Janitor) reconcileStorage() {
+	cutoff := time.Now().Add(-j.config.Orphans.MinAge)
+
+	var orphans int
+	keys := make([]string, 0, storedFilesBatch)
+	err := j.store.Walk(func(info *storage.BlobInfo) error {
+		if j.stopped() {
+			return errJanitorStopped
+		}
+
+		if info.ModTime.After(cutoff) {
+			return nil
+		}
+
+		keys = append(keys, info.Key)
+		if len(keys) < storedFilesBatch {
+			return nil
+		}
+
+		n, err := j.handleOrphans(keys)
+		orphans += n
+		keys = keys[:0]
+
+		return err
+	})
+	if err == nil && len(keys) > 0 {
+		var n int
+		n, err = j.handleOrphans(keys)
+		orphans += n
+	}
+
+	if err != nil && !errors.Is(err, errJanitorStopped) {
+		logger.Errorf("failed to reconcile storage: %v", err)
+	}
+
+	if orphans > 0 {
+		logger.Warnf("orphaned files in storage: count=%v action=%v", orphans, j.config.Orphans.Action)
+	}
+}
+
+// handleOrphans - reports or deletes the files of the keys that nothing references, returns their number.
+// References are checked again under the blob lock, a file stored again since the check is kept
+func (j *Janitor) handleOrphans(keys []string) (int, error) {
+	orphans, err := j.repo.GetUnreferenced(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range orphans {
+		if j.config.Orphans.Action != orphansDelete {
+			logger.Warnf("orphaned file: key=%v", key)
+			continue
+		}
+
+		deleted, err := j.blobs.Delete(key, blobDeleter(j.store))
+		if err != nil {
+			logger.Errorf("failed to delete orphaned file: key=%v: %v", key, err)
+			continue
+		}
+
+		if deleted {
+			logger.Infof("deleted orphaned file: key=%v", key)
+		}
+	}
+
+	return len(orphans), nil
+}
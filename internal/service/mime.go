@@ -0,0 +1,101 @@
+package service
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/config"
+)
+
+// detectMime - detects the type of the file by its first bytes and rewinds it
+func detectMime(content io.ReadSeeker) (*mimetype.MIME, error) {
+	detected, err := mimetype.DetectReader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return detected, nil
+}
+
+// resolveMime - chooses the mime stored for the file. The passed mime is kept if the content agrees with it
+// or the content can't tell, otherwise the detected one is stored and the mismatch is flagged
+func resolveMime(passed string, detected *mimetype.MIME) (mime string, mismatch bool) {
+	passed = baseMime(passed)
+	detectedMime := baseMime(detected.String())
+
+	if passed == "" {
+		return detectedMime, false
+	}
+
+	// The passed mime is the detected one or its parent, e.g. application/zip for a docx file
+	for m := detected; m != nil; m = m.Parent() {
+		if m.Is(passed) {
+			return detectedMime, false
+		}
+	}
+
+	// Formats without a signature are detected as unknown binary or plain text, the passed mime
+	// can't be checked then. A mime with a signature would have been detected
+	if mimetype.Lookup(passed) == nil &&
+		(detected.Is("application/octet-stream") || (detected.Is("text/plain") && strings.HasPrefix(passed, "text/"))) {
+		return passed, false
+	}
+
+	return detectedMime, true
+}
+
+// baseMime - mime without parameters in lower case
+func baseMime(mime string) string {
+	mime, _, _ = strings.Cut(mime, ";")
+
+	return strings.ToLower(strings.TrimSpace(mime))
+}
+
+// checkMime - checks the mime of the file against the allow and deny lists
+func checkMime(cfg config.DocumentsMime, mime string) error {
+	// A denied type denies its subtypes, e.g. application/x-elf denies application/x-executable.
+	// The root application/octet-stream denies only files of unknown types
+	types := []string{mime}
+	if detected := mimetype.Lookup(mime); detected != nil {
+		for m := detected.Parent(); m != nil && m.Parent() != nil; m = m.Parent() {
+			types = append(types, baseMime(m.String()))
+		}
+	}
+
+	for _, pattern := range cfg.Deny {
+		for _, t := range types {
+			if matchMime(pattern, t) {
+				return domain.ErrMimeNotAllowed
+			}
+		}
+	}
+
+	if len(cfg.Allow) == 0 {
+		return nil
+	}
+
+	for _, pattern := range cfg.Allow {
+		if matchMime(pattern, mime) {
+			return nil
+		}
+	}
+
+	return domain.ErrMimeNotAllowed
+}
+
+// matchMime - matches the mime with the pattern, type/* matches any subtype
+func matchMime(pattern, mime string) bool {
+	pattern = baseMime(pattern)
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mime, prefix+"/")
+	}
+
+	return pattern == mime
+}
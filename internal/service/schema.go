@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/repository"
+)
+
+const (
+	// schemaURL - location of the schema being compiled, schemas can't refer to other locations
+	schemaURL = "mem:///schema.json"
+
+	// maxFieldErrors - limit of field errors returned for one document
+	maxFieldErrors = 50
+)
+
+type SchemaService struct {
+	repo repository.Schema
+
+	mu       sync.Mutex
+	compiled map[string]compiledSchema
+}
+
+// compiledSchema - compiled schema is valid while the schema isn't updated
+type compiledSchema struct {
+	updatedAt time.Time
+	schema    *jsonschema.Schema
+}
+
+func NewSchemaService(repo repository.Schema) *SchemaService {
+	return &SchemaService{
+		repo:     repo,
+		compiled: make(map[string]compiledSchema),
+	}
+}
+
+func (s *SchemaService) Create(schema *domain.Schema, ownerId string) error {
+	if _, err := compileSchema(schema.Schema); err != nil {
+		return err
+	}
+
+	return s.repo.Create(schema, ownerId)
+}
+
+func (s *SchemaService) GetAll() ([]domain.Schema, error) {
+	return s.repo.GetAll()
+}
+
+func (s *SchemaService) GetByName(name string) (*domain.Schema, error) {
+	return s.repo.GetByName(name)
+}
+
+func (s *SchemaService) Update(name, userId string, schema json.RawMessage) error {
+	if _, err := compileSchema(schema); err != nil {
+		return err
+	}
+
+	return s.repo.Update(name, userId, schema)
+}
+
+func (s *SchemaService) Delete(name, userId string) error {
+	if err := s.repo.Delete(name, userId); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.compiled, name)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Validate - checks the JSON against the schema, returns *domain.ValidationError
+// with violations if the JSON doesn't match it. Empty JSON is validated as null
+func (s *SchemaService) Validate(name, data string) error {
+	schema, err := s.repo.GetByName(name)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := s.compile(schema)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if data != "" {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+		// Numbers are compared exactly, as the schema keywords expect
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return domain.ErrInvalidJSON
+		}
+	}
+
+	if err := compiled.Validate(value); err != nil {
+		var verr *jsonschema.ValidationError
+		if errors.As(err, &verr) {
+			return &domain.ValidationError{
+				Err:    domain.ErrDocumentDataIsInvalid,
+				Fields: fieldErrors(verr),
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// compile - returns the compiled schema from the cache, the schema is compiled again if it's been updated
+func (s *SchemaService) compile(schema *domain.Schema) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	cached, ok := s.compiled[schema.Name]
+	s.mu.Unlock()
+
+	if ok && cached.updatedAt.Equal(schema.UpdatedAt) {
+		return cached.schema, nil
+	}
+
+	compiled, err := compileSchema(schema.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.compiled[schema.Name] = compiledSchema{
+		updatedAt: schema.UpdatedAt,
+		schema:    compiled,
+	}
+	s.mu.Unlock()
+
+	return compiled, nil
+}
+
+// compileSchema - compiles the schema, returns *domain.ValidationError if it isn't a valid JSON Schema
+func compileSchema(schema json.RawMessage) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	// Remote and file references would let users read files and make requests from the server
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("references to %v aren't allowed", url)
+	}
+
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(schema)); err != nil {
+		return nil, &domain.ValidationError{
+			Err:    domain.ErrInvalidSchema,
+			Fields: []domain.FieldError{{Message: err.Error()}},
+		}
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		var verr *jsonschema.ValidationError
+		if errors.As(err, &verr) {
+			return nil, &domain.ValidationError{
+				Err:    domain.ErrInvalidSchema,
+				Fields: fieldErrors(verr),
+			}
+		}
+
+		return nil, &domain.ValidationError{
+			Err:    domain.ErrInvalidSchema,
+			Fields: []domain.FieldError{{Message: err.Error()}},
+		}
+	}
+
+	return compiled, nil
+}
+
+// fieldErrors - returns the innermost violations, they point to the exact values
+func fieldErrors(verr *jsonschema.ValidationError) []domain.FieldError {
+	fields := make([]domain.FieldError, 0)
+
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(fields) >= maxFieldErrors {
+			return
+		}
+
+		if len(e.Causes) == 0 {
+			fields = append(fields, domain.FieldError{
+				Field:   e.InstanceLocation,
+				Message: e.Message,
+			})
+		}
+
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+
+	return fields
+}
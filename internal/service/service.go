@@ -1,26 +1,90 @@
 package service
 
 import (
+	"encoding/json"
+	"io"
+	"time"
+
 	"github.com/sixojke/test-astral/domain"
 	"github.com/sixojke/test-astral/internal/config"
 	"github.com/sixojke/test-astral/internal/repository"
 	"github.com/sixojke/test-astral/pkg/auth"
 	"github.com/sixojke/test-astral/pkg/hash"
+	"github.com/sixojke/test-astral/pkg/storage"
 )
 
 type User interface {
 	SignUp(adminToken, login, password string) error
-	SignIn(login, password string) (accessToken string, err error)
-	GetUserIdByToken(token string) (userId string, err error)
-	DeleteSession(token string) error
+	SignIn(login, password string, client domain.Client) (*domain.Tokens, error)
+	Refresh(refreshToken string, client domain.Client) (*domain.Tokens, error)
+	CheckToken(accessToken string, claims *auth.Claims) error
+	GetSessions(userId, currentSessionId string) ([]domain.Session, error)
+	DeleteUserSession(sessionId, userId string) error
+	DeleteUserSessions(userId string) error
+	DeleteSession(token, userId string) error
 }
 
 type Document interface {
-	Create(document *domain.Document, userId string) error
-	GetByUser(userLogin, currentUserId string, params *domain.FilterParams) (*[]domain.Document, error)
+	Create(document *domain.Document, file *domain.File, userId string) (unknownGrants []string, err error)
+	GetByUser(userLogin, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error)
+	GetByFolder(folderId, currentUserId string, params *domain.FilterParams) (*domain.DocumentList, error)
+	Search(query, userId string, params *domain.FilterParams) ([]domain.SearchResult, error)
 	GetById(documentId, userId string) (*domain.Document, error)
-	CheckById(documentId, userId string) (bool, error)
+	OpenFile(filePath string) (io.ReadCloser, *storage.BlobInfo, error)
+	OpenFileRange(filePath string, offset, length int64) (io.ReadCloser, error)
+	StatFile(filePath string) (*storage.BlobInfo, error)
+	Update(documentId, userId string, update *domain.DocumentUpdate) (unknownGrants []string, err error)
+	PatchData(documentId, userId string, patch *domain.DataPatch) (*domain.Document, error)
+	UpdateFile(documentId, userId string, file *domain.File, mime string) error
 	Delete(documentId, userId string) error
+	GetVersions(documentId, userId string) (*[]domain.DocumentVersion, error)
+	GetVersion(documentId, userId string, version int) (*domain.DocumentVersion, error)
+	RestoreVersion(documentId, userId string, version int) error
+	GetGrants(documentId, userId string) ([]domain.Grant, error)
+	AddGrants(documentId, userId string, grants []domain.Grant) (unknown []string, err error)
+	DeleteGrants(documentId, userId string, subjects []string) (unknown []string, err error)
+	CreateShareLink(documentId, userId string, ttl time.Duration, maxDownloads *int) (*domain.ShareLink, error)
+	GetShareLinks(documentId, userId string) ([]domain.ShareLink, error)
+	DeleteShareLink(documentId, userId, linkId string) error
+	GetByShareToken(token string) (*domain.Document, error)
+}
+
+type Group interface {
+	Create(group *domain.Group, ownerId string, members []string) (unknown []string, err error)
+	GetByUser(userId string) ([]domain.Group, error)
+	GetById(groupId, userId string) (*domain.Group, error)
+	Rename(groupId, userId, name string) error
+	Delete(groupId, userId string) error
+	AddMembers(groupId, userId string, logins []string) (unknown []string, err error)
+	DeleteMembers(groupId, userId string, logins []string) (unknown []string, err error)
+}
+
+type Folder interface {
+	Create(folder *domain.Folder, userId string) error
+	GetByUser(userId string) ([]domain.Folder, error)
+	GetById(folderId, userId string) (*domain.Folder, error)
+	GetChildren(folderId, userId string) ([]domain.Folder, error)
+	Update(folderId, userId string, update *domain.FolderUpdate) error
+	Delete(folderId, userId string) error
+	GetGrants(folderId, userId string) ([]domain.Grant, error)
+	AddGrants(folderId, userId string, grants []domain.Grant) (unknown []string, err error)
+	DeleteGrants(folderId, userId string, subjects []string) (unknown []string, err error)
+}
+
+type Schema interface {
+	Create(schema *domain.Schema, ownerId string) error
+	GetAll() ([]domain.Schema, error)
+	GetByName(name string) (*domain.Schema, error)
+	Update(name, userId string, schema json.RawMessage) error
+	Delete(name, userId string) error
+}
+
+type Upload interface {
+	Create(upload *domain.Upload, userId string) error
+	GetById(uploadId, userId string) (*domain.Upload, error)
+	WriteChunk(uploadId, userId string, offset int64, chunk io.Reader, size int64) (int64, error)
+	Complete(uploadId, userId string, document *domain.Document) (unknownGrants []string, err error)
+	Delete(uploadId, userId string) error
 }
 
 type Deps struct {
@@ -28,16 +92,41 @@ type Deps struct {
 	Config       *config.Config
 	Hasher       hash.PasswordHasher
 	TokenManager auth.TokenManager
+	BlobStore    storage.BlobStore
 }
 
 type Service struct {
 	User
 	Document
+	Group
+	Folder
+	Schema
+	Upload
+
+	// UploadCleaner - background cleanup of abandoned uploads, started and stopped by the app
+	UploadCleaner *UploadCleaner
+	// Extractor - background text extraction of files, started and stopped by the app
+	Extractor *ContentExtractor
+	// Janitor - background purge of expired sessions and reconciliation of the storage, started and stopped by the app
+	Janitor *Janitor
 }
 
 func NewService(deps *Deps) *Service {
+	extractor := NewContentExtractor(deps.Repository.Content, deps.BlobStore, deps.Config.Documents.Extractor)
+	schemas := NewSchemaService(deps.Repository.Schema)
+	documents := NewDocumentService(deps.Repository.Document, deps.Repository.User, deps.Repository.Blob, deps.BlobStore,
+		extractor, schemas, deps.Config.Documents.ShareLinks, deps.Config.Documents.Mime)
+
 	return &Service{
 		NewUserService(deps.Repository.User, deps.Hasher, deps.Config.Authorization, deps.TokenManager),
-		NewDocumentService(deps.Repository.Document, deps.Repository.User),
+		documents,
+		NewGroupService(deps.Repository.Group),
+		NewFolderService(deps.Repository.Folder),
+		schemas,
+		NewUploadService(deps.Repository.Upload, deps.BlobStore, documents, deps.Config.Documents.Uploads),
+		NewUploadCleaner(deps.Repository.Upload, deps.BlobStore, deps.Config.Documents.Uploads.CleanupInterval),
+		extractor,
+		NewJanitor(deps.Repository.Janitor, deps.Repository.Blob, deps.BlobStore, deps.Config.Janitor,
+			deps.Config.Authorization.JWT.RefreshTokenTTL),
 	}
 }
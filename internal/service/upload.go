@@ -0,0 +1,348 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sixojke/test-astral/domain"
+	"github.com/sixojke/test-astral/internal/config"
+	"github.com/sixojke/test-astral/internal/repository"
+	"github.com/sixojke/test-astral/pkg/logger"
+	"github.com/sixojke/test-astral/pkg/storage"
+)
+
+// expiredUploadsBatch - uploads deleted by one query of the cleanup
+const expiredUploadsBatch = 100
+
+// UploadService - resumable uploads of large files. Chunks are stored in the blob storage
+// as they come, the completed upload is assembled into a document file
+type UploadService struct {
+	repo      repository.Upload
+	store     storage.BlobStore
+	documents Document
+	config    config.DocumentsUploads
+}
+
+func NewUploadService(repo repository.Upload, store storage.BlobStore, documents Document,
+	config config.DocumentsUploads) *UploadService {
+	return &UploadService{
+		repo:      repo,
+		store:     store,
+		documents: documents,
+		config:    config,
+	}
+}
+
+func (s *UploadService) Create(upload *domain.Upload, userId string) error {
+	if upload.Size <= 0 {
+		return domain.ErrInvalidUploadSize
+	}
+
+	if upload.Size > s.config.MaxFileSizeMb<<20 {
+		return domain.ErrFileIsTooLarge
+	}
+
+	upload.ExpiresAt = time.Now().Add(s.config.TTL)
+
+	return s.repo.Create(upload, userId)
+}
+
+func (s *UploadService) GetById(uploadId, userId string) (*domain.Upload, error) {
+	return s.repo.GetById(uploadId, userId)
+}
+
+// WriteChunk - stores the chunk of size bytes at the offset, returns the offset after it.
+// The chunk must start where the received part of the upload ends
+func (s *UploadService) WriteChunk(uploadId, userId string, offset int64, chunk io.Reader, size int64) (int64, error) {
+	if size <= 0 {
+		return 0, domain.ErrInvalidUploadSize
+	}
+
+	if size > s.config.MaxChunkSizeMb<<20 {
+		return 0, domain.ErrChunkIsTooLarge
+	}
+
+	upload, err := s.repo.GetById(uploadId, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	// Checked before the chunk is stored, so a retried chunk isn't read for nothing
+	if upload.Completing || offset != upload.Offset {
+		return 0, domain.ErrUploadOffsetMismatch
+	}
+
+	if offset+size > upload.Size {
+		return 0, domain.ErrChunkIsTooLarge
+	}
+
+	// Concurrent requests with the same offset store their chunks under different keys,
+	// only one of them is added to the upload
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return 0, err
+	}
+
+	key := fmt.Sprintf("uploads/%v/%v-%v", uploadId, offset, hex.EncodeToString(suffix))
+	if err := s.store.Put(key, io.LimitReader(chunk, size), size); err != nil {
+		logger.Errorf("failed to save upload chunk: %v", err)
+		return 0, err
+	}
+
+	newOffset, err := s.repo.AddChunk(uploadId, userId, &domain.UploadChunk{
+		Start:   offset,
+		Size:    size,
+		BlobKey: key,
+	}, time.Now().Add(s.config.TTL))
+	if err != nil {
+		deleteChunks(s.store, []string{key})
+
+		return 0, err
+	}
+
+	return newOffset, nil
+}
+
+// Complete - creates the document with the uploaded file and deletes the upload
+func (s *UploadService) Complete(uploadId, userId string, document *domain.Document) (unknownGrants []string, err error) {
+	upload, chunks, err := s.repo.StartCompleting(uploadId, userId, time.Now().Add(s.config.TTL))
+	if err != nil {
+		return nil, err
+	}
+
+	content := newChunkReader(s.store, chunks, upload.Size)
+	defer content.Close()
+
+	document.IsFile = true
+
+	unknownGrants, err = s.documents.Create(document, &domain.File{
+		Name:    upload.FileName,
+		Size:    upload.Size,
+		Content: content,
+	}, userId)
+	if err != nil {
+		if err := s.repo.CancelCompleting(uploadId); err != nil {
+			logger.Errorf("failed to cancel completing upload: uploadId=%v: %v", uploadId, err)
+		}
+
+		return nil, err
+	}
+
+	blobKeys, err := s.repo.FinishCompleting(uploadId, userId)
+	if err != nil {
+		logger.Errorf("failed to delete completed upload: uploadId=%v: %v", uploadId, err)
+
+		return unknownGrants, nil
+	}
+
+	deleteChunks(s.store, blobKeys)
+
+	return unknownGrants, nil
+}
+
+// Delete - cancels the upload and deletes its chunks
+func (s *UploadService) Delete(uploadId, userId string) error {
+	blobKeys, err := s.repo.Delete(uploadId, userId)
+	if err != nil {
+		return err
+	}
+
+	deleteChunks(s.store, blobKeys)
+
+	return nil
+}
+
+func deleteChunks(store storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Errorf("failed to delete upload chunk: key=%v: %v", key, err)
+		}
+	}
+}
+
+// UploadCleaner - deletes abandoned uploads with their chunks in the background
+type UploadCleaner struct {
+	repo     repository.Upload
+	store    storage.BlobStore
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewUploadCleaner(repo repository.Upload, store storage.BlobStore, interval time.Duration) *UploadCleaner {
+	return &UploadCleaner{
+		repo:     repo,
+		store:    store,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start - starts the periodic cleanup, zero interval disables it
+func (c *UploadCleaner) Start() {
+	if c.interval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop - waits for the cleanup in progress
+func (c *UploadCleaner) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+func (c *UploadCleaner) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.deleteExpired()
+		}
+	}
+}
+
+func (c *UploadCleaner) deleteExpired() {
+	for {
+		uploads, blobKeys, err := c.repo.DeleteExpired(expiredUploadsBatch)
+		if err != nil {
+			return
+		}
+
+		deleteChunks(c.store, blobKeys)
+
+		if uploads > 0 {
+			logger.Infof("deleted expired uploads: %v", uploads)
+		}
+
+		if uploads < expiredUploadsBatch {
+			return
+		}
+
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+	}
+}
+
+// chunkReader - reads the chunks of the upload as one file, chunks are opened on demand
+type chunkReader struct {
+	store  storage.BlobStore
+	chunks []domain.UploadChunk
+	size   int64
+
+	offset  int64
+	current io.ReadCloser // chunk part starting at offset, nil - not opened yet
+	end     int64         // end of the opened chunk
+}
+
+func newChunkReader(store storage.BlobStore, chunks []domain.UploadChunk, size int64) *chunkReader {
+	return &chunkReader{
+		store:  store,
+		chunks: chunks,
+		size:   size,
+	}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.offset >= r.size {
+			return 0, io.EOF
+		}
+
+		if r.current == nil {
+			if err := r.open(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.current.Read(p)
+		r.offset += int64(n)
+
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+
+			// A stored chunk shorter than its size would be opened at the same offset again
+			if r.offset < r.end {
+				return n, io.ErrUnexpectedEOF
+			}
+
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+// open - opens the rest of the chunk containing the offset
+func (r *chunkReader) open() error {
+	for _, chunk := range r.chunks {
+		if r.offset >= chunk.Start && r.offset < chunk.Start+chunk.Size {
+			skip := r.offset - chunk.Start
+
+			file, err := r.store.GetRange(chunk.BlobKey, skip, chunk.Size-skip)
+			if err != nil {
+				return fmt.Errorf("failed to open upload chunk: %w", err)
+			}
+
+			r.current = file
+			r.end = chunk.Start + chunk.Size
+
+			return nil
+		}
+	}
+
+	return io.ErrUnexpectedEOF
+}
+
+func (r *chunkReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+
+	return offset, nil
+}
+
+func (r *chunkReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+
+	err := r.current.Close()
+	r.current = nil
+
+	return err
+}
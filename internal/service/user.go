@@ -1,9 +1,12 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sixojke/test-astral/domain"
 	"github.com/sixojke/test-astral/internal/config"
 	"github.com/sixojke/test-astral/internal/repository"
@@ -17,15 +20,25 @@ type UserService struct {
 	hasher       hash.PasswordHasher
 	authConfig   config.Authorization
 	tokenManager auth.TokenManager
+	denylist     *auth.Denylist
+	// dummyHash - verified for unknown logins, so they take as long as known ones
+	dummyHash string
 }
 
 func NewUserService(repo repository.User, hasher hash.PasswordHasher, authConfig config.Authorization,
 	tokenManager auth.TokenManager) *UserService {
+	dummyHash, err := hasher.Hash("dummy password")
+	if err != nil {
+		logger.Errorf("failed to hash dummy password: %v", err)
+	}
+
 	return &UserService{
 		repo:         repo,
 		hasher:       hasher,
 		authConfig:   authConfig,
 		tokenManager: tokenManager,
+		denylist:     auth.NewDenylist(),
+		dummyHash:    dummyHash,
 	}
 }
 
@@ -51,47 +64,247 @@ func (s *UserService) SignUp(adminToken, login, password string) error {
 	return nil
 }
 
-func (s *UserService) SignIn(login, password string) (accessToken string, err error) {
+// maxUserAgentLength - user agents are stored truncated to the column size
+const maxUserAgentLength = 512
+
+func (s *UserService) SignIn(login, password string, client domain.Client) (*domain.Tokens, error) {
+	userId, passwordHash, err := s.repo.GetCredentials(login)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Unknown logins can't be told from wrong passwords by the response time
+			s.hasher.Verify(password, s.dummyHash)
+		} else {
+			logger.Errorf("failed to get user credentials: %v", err)
+		}
+
+		return nil, err
+	}
+
+	ok, rehash, err := s.hasher.Verify(password, passwordHash)
+	if err != nil {
+		logger.Errorf("failed to verify password: userId=%v: %v", userId, err)
+		return nil, err
+	}
+
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if rehash {
+		s.rehashPassword(userId, password)
+	}
+
+	return s.createSession(userId, client)
+}
+
+// rehashPassword - replaces the outdated hash of the password, the sign in doesn't fail if it can't be replaced
+func (s *UserService) rehashPassword(userId, password string) {
 	passwordHash, err := s.hasher.Hash(password)
 	if err != nil {
-		return "", err
+		logger.Errorf("failed to hash password: %v", err)
+		return
 	}
 
-	userId, err := s.repo.GetByCredentials(login, passwordHash)
+	if err := s.repo.UpdatePassword(userId, passwordHash); err != nil {
+		logger.Errorf("failed to update password hash: userId=%v: %v", userId, err)
+	}
+}
+
+func (s *UserService) createSession(userId string, client domain.Client) (*domain.Tokens, error) {
+	session := &domain.Session{
+		Id:     uuid.NewString(),
+		UserId: userId,
+	}
+	setSessionClient(session, client)
+
+	tokens, sessionTokens, err := s.newTokens(session)
 	if err != nil {
-		if !errors.Is(err, domain.ErrUserNotFound) {
-			logger.Errorf("failed to get user by credentials: %v", err)
+		return nil, err
+	}
+
+	if err := s.repo.CreateSession(session, sessionTokens); err != nil {
+		logger.Errorf("failed to create session: %v", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh - issues new tokens of the session in exchange for the refresh token, the refresh token can be used once
+// The client of the session is replaced by the one that refreshes it
+func (s *UserService) Refresh(refreshToken string, client domain.Client) (*domain.Tokens, error) {
+	session, err := s.repo.UseRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			s.denySession(session.Id)
 		}
 
-		return "", err
+		return nil, err
 	}
 
-	return s.createSession(userId)
+	setSessionClient(session, client)
+
+	tokens, sessionTokens, err := s.newTokens(session)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.repo.RotateTokens(session, sessionTokens)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidRefreshToken) {
+			logger.Errorf("failed to rotate session tokens: %v", err)
+		}
+
+		return nil, err
+	}
+
+	// Replaced tokens are rejected by their hashes until they expire
+	for _, token := range revoked {
+		s.denylist.Add(token.TokenHash, token.ExpiresAt)
+	}
+
+	return tokens, nil
+}
+
+func setSessionClient(session *domain.Session, client domain.Client) {
+	userAgent := []rune(client.UserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session.UserAgent = string(userAgent)
+	session.IP = client.IP
 }
 
-func (s *UserService) createSession(userId string) (accessToken string, err error) {
-	accessToken, err = s.tokenManager.NewJWT(userId, s.authConfig.JWT.AccessTokenTTL)
+// newTokens - issues the access and refresh tokens of the session
+func (s *UserService) newTokens(session *domain.Session) (*domain.Tokens, *domain.SessionTokens, error) {
+	now := time.Now()
+
+	accessToken, err := s.tokenManager.NewJWT(session.UserId, session.Id, s.authConfig.JWT.AccessTokenTTL)
 	if err != nil {
 		logger.Errorf("failed to create access token: %v", err)
-		return accessToken, err
+		return nil, nil, err
+	}
+
+	refreshToken, err := s.tokenManager.NewRefreshToken()
+	if err != nil {
+		logger.Errorf("failed to create refresh token: %v", err)
+		return nil, nil, err
 	}
 
-	if err = s.repo.AddSession(domain.Session{
-		UserId:      userId,
-		AccessToken: accessToken,
-		ExpiresAt:   time.Now().Add(s.authConfig.JWT.AccessTokenTTL),
-	}); err != nil {
-		logger.Errorf("failed to add session: %v", err)
-		return "", err
+	tokens := &domain.Tokens{
+		AccessToken:     accessToken,
+		AccessExpiresAt: now.Add(s.authConfig.JWT.AccessTokenTTL),
+		RefreshToken:    refreshToken,
 	}
 
-	return accessToken, err
+	return tokens, &domain.SessionTokens{
+		AccessTokenHash:  hashToken(accessToken),
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        now.Add(s.authConfig.JWT.RefreshTokenTTL),
+	}, nil
 }
 
-func (s *UserService) GetUserIdByToken(token string) (userId string, err error) {
-	return s.repo.GetUserIdBySession(token)
+// hashToken - only hashes of the tokens are stored, a leaked table doesn't give valid tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckToken - checks that the access token verified by the token manager isn't revoked.
+// The database is looked up if the revocation check is on or the token is issued before sessions
+func (s *UserService) CheckToken(accessToken string, claims *auth.Claims) error {
+	tokenHash := hashToken(accessToken)
+	if s.denylist.Contains(tokenHash) || s.denylist.Contains(claims.SessionId) {
+		return domain.ErrUserUnauthorized
+	}
+
+	if !s.authConfig.RevocationCheck && claims.SessionId != "" {
+		return nil
+	}
+
+	userId, err := s.repo.GetUserIdBySession(tokenHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrUserUnauthorized
+		}
+
+		logger.Errorf("failed to get userId by session: %v", err)
+		return err
+	}
+
+	if userId != claims.Subject {
+		return domain.ErrUserUnauthorized
+	}
+
+	return nil
+}
+
+// DeleteSession - deletes the session the access token of the user is issued to
+func (s *UserService) DeleteSession(token, userId string) error {
+	if err := s.repo.DeleteSession(hashToken(token), userId); err != nil {
+		if !errors.Is(err, domain.ErrSessionNotFound) {
+			logger.Errorf("failed to delete session: %v", err)
+		}
+
+		return err
+	}
+
+	if claims, err := s.tokenManager.Parse(token); err == nil {
+		s.denySession(claims.SessionId)
+	}
+
+	return nil
+}
+
+// GetSessions - active sessions of the user, the session of the request is marked as current
+func (s *UserService) GetSessions(userId, currentSessionId string) ([]domain.Session, error) {
+	sessions, err := s.repo.GetSessions(userId)
+	if err != nil {
+		logger.Errorf("failed to get sessions: %v", err)
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentSessionId
+	}
+
+	return sessions, nil
+}
+
+// DeleteUserSession - revokes the session of the user with all its tokens
+func (s *UserService) DeleteUserSession(sessionId, userId string) error {
+	if err := s.repo.DeleteUserSession(sessionId, userId); err != nil {
+		if !errors.Is(err, domain.ErrSessionNotFound) {
+			logger.Errorf("failed to delete session: %v", err)
+		}
+
+		return err
+	}
+
+	s.denySession(sessionId)
+
+	return nil
+}
+
+// DeleteUserSessions - revokes all sessions of the user, including the current one
+func (s *UserService) DeleteUserSessions(userId string) error {
+	sessionIds, err := s.repo.DeleteUserSessions(userId)
+	if err != nil {
+		logger.Errorf("failed to delete sessions: %v", err)
+		return err
+	}
+
+	for _, sessionId := range sessionIds {
+		s.denySession(sessionId)
+	}
+
+	return nil
 }
 
-func (s *UserService) DeleteSession(token string) error {
-	return s.repo.DeleteSession(token)
+// denySession - rejects access tokens of the revoked session until the last of them expires
+func (s *UserService) denySession(sessionId string) {
+	s.denylist.Add(sessionId, time.Now().Add(s.authConfig.JWT.AccessTokenTTL))
 }
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Denylist - revoked tokens and sessions of this process, an entry is kept until the tokens it denies expire
+type Denylist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+	purged  time.Time
+}
+
+// purgeInterval - how often expired entries are removed
+const purgeInterval = time.Minute
+
+func NewDenylist() *Denylist {
+	return &Denylist{
+		entries: make(map[string]time.Time),
+		purged:  time.Now(),
+	}
+}
+
+// Add - denies the token or session id until the time
+func (d *Denylist) Add(id string, until time.Time) {
+	if id == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.purged) > purgeInterval {
+		for id, expiresAt := range d.entries {
+			if now.After(expiresAt) {
+				delete(d.entries, id)
+			}
+		}
+
+		d.purged = now
+	}
+
+	if until.After(d.entries[id]) {
+		d.entries[id] = until
+	}
+}
+
+func (d *Denylist) Contains(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	until, ok := d.entries[id]
+
+	return ok && time.Now().Before(until)
+}
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -8,9 +11,19 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+// refreshTokenLength - random bytes of the refresh token
+const refreshTokenLength = 32
+
 type TokenManager interface {
-	NewJWT(userId string, ttl time.Duration) (string, error)
-	Parse(accessToken string) (string, error)
+	NewJWT(userId, sessionId string, ttl time.Duration) (string, error)
+	Parse(accessToken string) (*Claims, error)
+	NewRefreshToken() (string, error)
+}
+
+// Claims - claims of the access token, sid is the session the token is issued to
+type Claims struct {
+	jwt.StandardClaims
+	SessionId string `json:"sid,omitempty"`
 }
 
 type Manager struct {
@@ -25,31 +38,52 @@ func NewManager(signingKey string) (*Manager, error) {
 	return &Manager{signingKey: signingKey}, nil
 }
 
-func (m *Manager) NewJWT(userId string, ttl time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(ttl).Unix(),
-		Subject:   userId,
+func (m *Manager) NewJWT(userId, sessionId string, ttl time.Duration) (string, error) {
+	// jti makes tokens issued in the same second unique
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        hex.EncodeToString(id),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+			Subject:   userId,
+		},
+		SessionId: sessionId,
 	})
 
 	return token.SignedString([]byte(m.signingKey))
 }
 
-func (m *Manager) Parse(accessToken string) (string, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (i interface{}, err error) {
+// Parse - verifies the signature and the expiration of the access token
+func (m *Manager) Parse(accessToken string) (*Claims, error) {
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(accessToken, &claims, func(token *jwt.Token) (i interface{}, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
 		return []byte(m.signingKey), nil
-	})
-	if err != nil {
-		return "", err
+	}); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", fmt.Errorf("error get user claims from token")
+	return &claims, nil
+}
+
+func (m *Manager) NewRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
 
-	return claims["sub"].(string), nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
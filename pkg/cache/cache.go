@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache - in-memory LRU cache with a TTL for every entry
+type Cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64 // bumped by every invalidation
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type entry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// Stats - cache hit/miss counters
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *Cache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	e := elem.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		c.removeElement(elem)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits.Add(1)
+
+	return e.value, true
+}
+
+func (c *Cache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+func (c *Cache) set(key string, value any) {
+	expiresAt := time.Now().Add(c.ttl)
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Generation - returns the invalidation counter, taken before a value is loaded for SetIfGeneration
+func (c *Cache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// SetIfGeneration - stores the value unless the cache was invalidated since the generation was taken,
+// so a value loaded before a change isn't cached after the change invalidated it
+func (c *Cache) SetIfGeneration(key string, value any, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return false
+	}
+
+	c.set(key, value)
+
+	return true
+}
+
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// DeletePrefix - removes all entries whose key starts with prefix
+func (c *Cache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// Purge - removes all entries
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry).key)
+}
@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		maxEntries int
+		run        func(c *Cache)
+		present    []string
+		absent     []string
+	}{
+		{
+			name:       "set and get",
+			ttl:        time.Minute,
+			maxEntries: 10,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+			},
+			present: []string{"a", "b"},
+		},
+		{
+			name:       "least recently used is evicted",
+			ttl:        time.Minute,
+			maxEntries: 2,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Set("c", 3)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name:       "get makes the entry recent",
+			ttl:        time.Minute,
+			maxEntries: 2,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Get("a")
+				c.Set("c", 3)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name:       "set of an existing key makes it recent",
+			ttl:        time.Minute,
+			maxEntries: 2,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Set("a", 3)
+				c.Set("c", 4)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name:       "no limit",
+			ttl:        time.Minute,
+			maxEntries: 0,
+			run: func(c *Cache) {
+				for _, key := range []string{"a", "b", "c", "d"} {
+					c.Set(key, key)
+				}
+			},
+			present: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:       "expired entry",
+			ttl:        time.Millisecond,
+			maxEntries: 10,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				time.Sleep(5 * time.Millisecond)
+			},
+			absent: []string{"a"},
+		},
+		{
+			name:       "delete",
+			ttl:        time.Minute,
+			maxEntries: 10,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Delete("a")
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+		},
+		{
+			name:       "delete prefix",
+			ttl:        time.Minute,
+			maxEntries: 10,
+			run: func(c *Cache) {
+				c.Set("doc:1:a", 1)
+				c.Set("doc:1:b", 2)
+				c.Set("doc:10:a", 3)
+				c.Set("list:1", 4)
+				c.DeletePrefix("doc:1:")
+			},
+			present: []string{"doc:10:a", "list:1"},
+			absent:  []string{"doc:1:a", "doc:1:b"},
+		},
+		{
+			name:       "purge",
+			ttl:        time.Minute,
+			maxEntries: 10,
+			run: func(c *Cache) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Purge()
+			},
+			absent: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(tt.ttl, tt.maxEntries)
+			tt.run(c)
+
+			for _, key := range tt.present {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("Get(%q) missed, want a hit", key)
+				}
+			}
+
+			for _, key := range tt.absent {
+				if value, ok := c.Get(key); ok {
+					t.Errorf("Get(%q) = %v, want a miss", key, value)
+				}
+			}
+		})
+	}
+}
+
+func TestSetIfGeneration(t *testing.T) {
+	tests := []struct {
+		name    string
+		between func(c *Cache) // runs after the generation is taken
+		stored  bool
+	}{
+		{name: "nothing changed", between: func(c *Cache) {}, stored: true},
+		{name: "other entry set", between: func(c *Cache) { c.Set("other", 1) }, stored: true},
+		{name: "expired entry dropped by get", between: func(c *Cache) { c.Get("expired") }, stored: true},
+		{name: "delete", between: func(c *Cache) { c.Delete("other") }, stored: false},
+		{name: "delete prefix", between: func(c *Cache) { c.DeletePrefix("doc:") }, stored: false},
+		{name: "purge", between: func(c *Cache) { c.Purge() }, stored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(time.Minute, 10)
+			c.items["expired"] = c.order.PushFront(&entry{key: "expired", expiresAt: time.Now().Add(-time.Second)})
+
+			generation := c.Generation()
+			tt.between(c)
+
+			if stored := c.SetIfGeneration("key", 1, generation); stored != tt.stored {
+				t.Fatalf("SetIfGeneration() = %v, want %v", stored, tt.stored)
+			}
+
+			if _, ok := c.Get("key"); ok != tt.stored {
+				t.Errorf("Get() hit = %v, want %v", ok, tt.stored)
+			}
+		})
+	}
+}
+
+// TestSetIfGenerationConcurrent - loads racing an invalidation never store their values
+func TestSetIfGenerationConcurrent(t *testing.T) {
+	c := New(time.Minute, 0)
+
+	const loaders = 50
+
+	var taken, loaded sync.WaitGroup
+	invalidated := make(chan struct{})
+	stored := make(chan bool, loaders)
+
+	taken.Add(loaders)
+	loaded.Add(loaders)
+	for i := 0; i < loaders; i++ {
+		go func(i int) {
+			defer loaded.Done()
+
+			generation := c.Generation()
+			taken.Done()
+			<-invalidated
+
+			stored <- c.SetIfGeneration(fmt.Sprintf("key:%d", i), i, generation)
+		}(i)
+	}
+
+	taken.Wait()
+	c.DeletePrefix("key:")
+	close(invalidated)
+	loaded.Wait()
+	close(stored)
+
+	for ok := range stored {
+		if ok {
+			t.Fatal("SetIfGeneration() stored a value loaded before the invalidation")
+		}
+	}
+
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Errorf("Stats().Entries = %v, want 0", stats.Entries)
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := New(time.Minute, 10)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("c")
+
+	want := Stats{Hits: 2, Misses: 1, Entries: 2}
+	if stats := c.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,188 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextSize - limit of the extracted text, the rest of the file isn't indexed.
+// Postgres can't build a tsvector bigger than 1MB, so the text is kept well below it
+const MaxTextSize = 256 << 10
+
+var ErrUnsupported = errors.New("unsupported file format")
+
+type format int
+
+const (
+	formatUnknown format = iota
+	formatText
+	formatHTML
+	formatPDF
+	formatDOCX
+	formatPPTX
+	formatXLSX
+	formatODF
+)
+
+var mimeFormats = map[string]format{
+	"text/plain":            formatText,
+	"text/markdown":         formatText,
+	"text/x-markdown":       formatText,
+	"text/csv":              formatText,
+	"application/json":      formatText,
+	"text/html":             formatHTML,
+	"application/xhtml+xml": formatHTML,
+	"application/pdf":       formatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   formatDOCX,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": formatPPTX,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         formatXLSX,
+	"application/vnd.oasis.opendocument.text":                                   formatODF,
+	"application/vnd.oasis.opendocument.presentation":                           formatODF,
+	"application/vnd.oasis.opendocument.spreadsheet":                            formatODF,
+}
+
+var extFormats = map[string]format{
+	".txt":      formatText,
+	".md":       formatText,
+	".markdown": formatText,
+	".csv":      formatText,
+	".json":     formatText,
+	".html":     formatHTML,
+	".htm":      formatHTML,
+	".pdf":      formatPDF,
+	".docx":     formatDOCX,
+	".pptx":     formatPPTX,
+	".xlsx":     formatXLSX,
+	".odt":      formatODF,
+	".odp":      formatODF,
+	".ods":      formatODF,
+}
+
+// Supported - reports whether the text of the file can be extracted
+func Supported(mime, name string) bool {
+	return detect(mime, name) != formatUnknown
+}
+
+// Text - extracts plain text of the file, the format is detected by the mime type
+// and then by the file name extension. Returns ErrUnsupported for unknown formats
+func Text(r io.ReaderAt, size int64, mime, name string) (text string, err error) {
+	// Parsers of binary formats may panic on malformed files
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("failed to parse file: %v", p)
+		}
+	}()
+
+	switch detect(mime, name) {
+	case formatText:
+		text, err = plainText(r, size)
+	case formatHTML:
+		text, err = htmlText(r, size)
+	case formatPDF:
+		text, err = pdfText(r, size)
+	case formatDOCX:
+		text, err = docxText(r, size)
+	case formatPPTX:
+		text, err = pptxText(r, size)
+	case formatXLSX:
+		text, err = xlsxText(r, size)
+	case formatODF:
+		text, err = odfText(r, size)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return sanitize(text), nil
+}
+
+func detect(mime, name string) format {
+	// Drop parameters like "; charset=utf-8"
+	mime, _, _ = strings.Cut(mime, ";")
+	mime = strings.ToLower(strings.TrimSpace(mime))
+
+	if f, ok := mimeFormats[mime]; ok {
+		return f
+	}
+
+	if f, ok := extFormats[strings.ToLower(path.Ext(name))]; ok {
+		return f
+	}
+
+	if strings.HasPrefix(mime, "text/") {
+		return formatText
+	}
+
+	return formatUnknown
+}
+
+func plainText(r io.ReaderAt, size int64) (string, error) {
+	b, err := io.ReadAll(io.NewSectionReader(r, 0, min(size, MaxTextSize)))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// sanitize - makes the text storable in Postgres: valid UTF-8 without NUL bytes,
+// whitespace runs are collapsed and the text is cut to MaxTextSize
+func sanitize(text string) string {
+	text = strings.ToValidUTF8(text, " ")
+	text = strings.ReplaceAll(text, "\x00", " ")
+
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+
+		if b.Len()+len(line)+1 > MaxTextSize {
+			b.WriteString(truncate(line, MaxTextSize-b.Len()))
+			break
+		}
+
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// truncate - cuts the string to n bytes without breaking a rune
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// limitedWriter - collects text until MaxTextSize is reached
+type limitedWriter struct {
+	strings.Builder
+}
+
+func (w *limitedWriter) full() bool {
+	return w.Len() >= MaxTextSize
+}
+
+func (w *limitedWriter) write(s string) {
+	if !w.full() {
+		w.WriteString(s)
+	}
+}
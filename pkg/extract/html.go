@@ -0,0 +1,59 @@
+package extract
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxMarkupSize - limit of markup files, text of HTML and XML is much smaller than the markup
+const maxMarkupSize = 32 << 20
+
+// blockElements - elements whose text is put on its own line
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Title: true, atom.Pre: true, atom.Blockquote: true, atom.Td: true, atom.Th: true,
+}
+
+func htmlText(r io.ReaderAt, size int64) (string, error) {
+	z := html.NewTokenizer(io.NewSectionReader(r, 0, min(size, maxMarkupSize)))
+
+	var w limitedWriter
+	skip := 0
+	for !w.full() {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return w.String(), nil
+			}
+			return "", z.Err()
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch a := atom.Lookup(name); {
+			case a == atom.Script || a == atom.Style || a == atom.Noscript:
+				skip++
+			case blockElements[a]:
+				w.write("\n")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch a := atom.Lookup(name); {
+			case a == atom.Script || a == atom.Style || a == atom.Noscript:
+				if skip > 0 {
+					skip--
+				}
+			case blockElements[a]:
+				w.write("\n")
+			}
+		case html.TextToken:
+			if skip == 0 {
+				w.write(string(z.Text()))
+			}
+		}
+	}
+
+	return w.String(), nil
+}
@@ -0,0 +1,176 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xmlText - describes where the text is in the XML part of an office document
+type xmlText struct {
+	// text - elements with the text, nil means the text of any element
+	text map[string]bool
+	// block - elements whose text is put on its own line
+	block map[string]bool
+	// space - empty elements standing for spaces and tabs
+	space map[string]bool
+}
+
+var (
+	// WordprocessingML: paragraphs w:p with runs of w:t
+	docxXML = xmlText{
+		text:  map[string]bool{"t": true},
+		block: map[string]bool{"p": true, "br": true, "cr": true},
+		space: map[string]bool{"tab": true},
+	}
+	// DrawingML of slides: paragraphs a:p with runs of a:t
+	pptxXML = xmlText{
+		text:  map[string]bool{"t": true},
+		block: map[string]bool{"p": true, "br": true},
+	}
+	// SpreadsheetML shared strings: string items si with runs of t
+	xlsxXML = xmlText{
+		text:  map[string]bool{"t": true},
+		block: map[string]bool{"si": true},
+	}
+	// OpenDocument: paragraphs text:p and headings text:h
+	odfXML = xmlText{
+		block: map[string]bool{"p": true, "h": true, "line-break": true, "table-cell": true},
+		space: map[string]bool{"s": true, "tab": true},
+	}
+)
+
+func docxText(r io.ReaderAt, size int64) (string, error) {
+	return zipText(r, size, docxXML, func(name string) bool {
+		return name == "word/document.xml"
+	})
+}
+
+func pptxText(r io.ReaderAt, size int64) (string, error) {
+	return zipText(r, size, pptxXML, func(name string) bool {
+		return strings.HasPrefix(name, "ppt/slides/slide") && path.Ext(name) == ".xml"
+	})
+}
+
+func xlsxText(r io.ReaderAt, size int64) (string, error) {
+	return zipText(r, size, xlsxXML, func(name string) bool {
+		return name == "xl/sharedStrings.xml"
+	})
+}
+
+func odfText(r io.ReaderAt, size int64) (string, error) {
+	return zipText(r, size, odfXML, func(name string) bool {
+		return name == "content.xml"
+	})
+}
+
+// zipText - extracts text of the XML parts of the zip archive matching the filter,
+// the parts are read in the natural order of their names so slide10 goes after slide9
+func zipText(r io.ReaderAt, size int64, x xmlText, match func(name string) bool) (string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	var parts []*zip.File
+	for _, file := range archive.File {
+		if match(file.Name) {
+			parts = append(parts, file)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", errors.New("document parts not found")
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return naturalLess(parts[i].Name, parts[j].Name)
+	})
+
+	var w limitedWriter
+	for _, part := range parts {
+		if w.full() {
+			break
+		}
+
+		if err := x.read(part, &w); err != nil {
+			return "", fmt.Errorf("failed to read %v: %w", part.Name, err)
+		}
+	}
+
+	return w.String(), nil
+}
+
+func (x xmlText) read(file *zip.File, w *limitedWriter) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// The uncompressed size in the header can't be trusted, the archive may be a zip bomb
+	decoder := xml.NewDecoder(io.LimitReader(rc, maxMarkupSize))
+
+	depth := 0
+	for !w.full() {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case x.text[t.Name.Local]:
+				depth++
+			case x.block[t.Name.Local]:
+				w.write("\n")
+			case x.space[t.Name.Local]:
+				w.write(" ")
+			}
+		case xml.EndElement:
+			switch {
+			case x.text[t.Name.Local]:
+				depth--
+			case x.block[t.Name.Local]:
+				w.write("\n")
+			}
+		case xml.CharData:
+			if x.text == nil || depth > 0 {
+				w.write(string(t))
+			}
+		}
+	}
+
+	return nil
+}
+
+// naturalLess - compares names by their numeric suffix if the rest is equal
+func naturalLess(a, b string) bool {
+	aBase, aNum := splitNumber(strings.TrimSuffix(a, path.Ext(a)))
+	bBase, bNum := splitNumber(strings.TrimSuffix(b, path.Ext(b)))
+	if aBase != bBase || aNum == bNum {
+		return a < b
+	}
+
+	return aNum < bNum
+}
+
+func splitNumber(s string) (string, int) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+
+	n, _ := strconv.Atoi(s[i:])
+	return s[:i], n
+}
@@ -0,0 +1,41 @@
+package extract
+
+import (
+	"io"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func pdfText(r io.ReaderAt, size int64) (string, error) {
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	var w limitedWriter
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= reader.NumPage() && !w.full(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		// Fonts are shared between pages, parsing their charmaps once is much faster
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return "", err
+		}
+
+		w.write(text)
+		w.write("\n")
+	}
+
+	return w.String(), nil
+}
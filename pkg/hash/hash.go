@@ -1,28 +1,118 @@
 package hash
 
 import (
-	"crypto/sha1"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 )
 
+var ErrInvalidHash = errors.New("invalid password hash")
+
 type PasswordHasher interface {
 	Hash(password string) (string, error)
+	// Verify - checks the password against the hash, rehash reports that the hash is outdated
+	// and the password should be hashed again
+	Verify(password, hash string) (ok, rehash bool, err error)
 }
 
-type SHA1Hasher struct {
-	salt string
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+
+	// Limits of the parameters of stored hashes, a hash beyond them would stall or exhaust the server on sign in
+	argon2MaxMemory     = 1 << 20 // KiB
+	argon2MaxIterations = 64
+)
+
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
 }
 
-func NewSHA1Hasher(salt string) *SHA1Hasher {
-	return &SHA1Hasher{salt: salt}
+// Argon2Hasher - argon2id hashes with a random salt in the PHC string format.
+// Hashes of the previous SHA-1 hasher are verified by the legacy hasher and reported for rehash
+type Argon2Hasher struct {
+	params Argon2Params
+	legacy *SHA1Hasher
 }
 
-func (h *SHA1Hasher) Hash(password string) (string, error) {
-	hash := sha1.New()
+func NewArgon2Hasher(params Argon2Params, legacy *SHA1Hasher) *Argon2Hasher {
+	return &Argon2Hasher{
+		params: params,
+		legacy: legacy,
+	}
+}
 
-	if _, err := hash.Write([]byte(password)); err != nil {
+func (h *Argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2Hasher) Verify(password, hash string) (ok, rehash bool, err error) {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		if h.legacy == nil {
+			return false, false, ErrInvalidHash
+		}
+
+		return h.legacy.Verify(password, hash)
+	}
+
+	params, salt, key, err := parseArgon2(hash)
+	if err != nil {
+		return false, false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false, nil
+	}
+
+	return true, params != h.params || len(key) != argon2KeyLength, nil
+}
+
+// parseArgon2 - parses $argon2id$v=19$m=...,t=...,p=...$salt$key
+func parseArgon2(hash string) (params Argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	// argon2.IDKey panics on zero iterations or parallelism
+	if params.Iterations < 1 || params.Iterations > argon2MaxIterations || params.Parallelism < 1 ||
+		params.Memory < 8*uint32(params.Parallelism) || params.Memory > argon2MaxMemory {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	return params, salt, key, nil
 }
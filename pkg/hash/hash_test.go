@@ -0,0 +1,115 @@
+package hash
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// testParams - cheap parameters, the tests check the format and not the strength
+var testParams = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestParseArgon2(t *testing.T) {
+	tests := []struct {
+		name   string
+		hash   string
+		params Argon2Params
+		err    error
+	}{
+		{
+			name:   "valid",
+			hash:   "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U",
+			params: Argon2Params{Memory: 65536, Iterations: 3, Parallelism: 2},
+		},
+		{name: "empty", hash: "", err: ErrInvalidHash},
+		{name: "missing key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", err: ErrInvalidHash},
+		{name: "extra part", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5$a2V5", err: ErrInvalidHash},
+		{name: "old version", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "no version", hash: "$argon2id$m=64,t=1,p=1$c2FsdA$a2V5$", err: ErrInvalidHash},
+		{name: "malformed params", hash: "$argon2id$v=19$m=64;t=1;p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "parallelism overflow", hash: "$argon2id$v=19$m=64,t=1,p=256$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "zero iterations", hash: "$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "too many iterations", hash: "$argon2id$v=19$m=64,t=4294967295,p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "zero parallelism", hash: "$argon2id$v=19$m=64,t=1,p=0$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "memory below 8 per lane", hash: "$argon2id$v=19$m=31,t=1,p=4$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "oversized memory", hash: "$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "salt not base64", hash: "$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5", err: ErrInvalidHash},
+		{name: "padded key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5a2U=", err: ErrInvalidHash},
+		{name: "empty key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$", err: ErrInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, _, err := parseArgon2(tt.hash)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseArgon2(%q) error = %v, want %v", tt.hash, err, tt.err)
+			}
+
+			if err == nil && params != tt.params {
+				t.Errorf("parseArgon2(%q) params = %+v, want %+v", tt.hash, params, tt.params)
+			}
+		})
+	}
+}
+
+func TestArgon2HasherVerify(t *testing.T) {
+	legacy := NewSHA1Hasher("salt")
+	hasher := NewArgon2Hasher(testParams, legacy)
+
+	current, err := hasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := NewArgon2Hasher(Argon2Params{Memory: 32, Iterations: 1, Parallelism: 1}, nil).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hashes with a key shorter than the current one are rehashed as well
+	salt := []byte("saltsaltsaltsalt")
+	short := fmt.Sprintf("$argon2id$v=%d$m=64,t=1,p=1$%s$%s", argon2.Version, base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("password"), salt, 1, 64, 1, 16)))
+
+	legacyHash, err := legacy.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		hasher   *Argon2Hasher
+		password string
+		hash     string
+		ok       bool
+		rehash   bool
+		err      error
+	}{
+		{name: "current params", hasher: hasher, password: "password", hash: current, ok: true},
+		{name: "wrong password", hasher: hasher, password: "passw0rd", hash: current},
+		{name: "old params", hasher: hasher, password: "password", hash: old, ok: true, rehash: true},
+		{name: "old params wrong password", hasher: hasher, password: "passw0rd", hash: old},
+		{name: "short key", hasher: hasher, password: "password", hash: short, ok: true, rehash: true},
+		{name: "legacy sha1", hasher: hasher, password: "password", hash: legacyHash, ok: true, rehash: true},
+		{name: "legacy sha1 wrong password", hasher: hasher, password: "passw0rd", hash: legacyHash},
+		{name: "legacy without legacy hasher", hasher: NewArgon2Hasher(testParams, nil), password: "password", hash: legacyHash,
+			err: ErrInvalidHash},
+		{name: "malformed", hasher: hasher, password: "password", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", err: ErrInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := tt.hasher.Verify(tt.password, tt.hash)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.err)
+			}
+
+			if ok != tt.ok || rehash != tt.rehash {
+				t.Errorf("Verify() = (%v, %v), want (%v, %v)", ok, rehash, tt.ok, tt.rehash)
+			}
+		})
+	}
+}
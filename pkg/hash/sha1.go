@@ -0,0 +1,41 @@
+package hash
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"fmt"
+)
+
+// SHA1Hasher - legacy hasher, its hashes are the hex of the global salt followed by the SHA-1 digest.
+// Kept to verify passwords of users that haven't signed in since the switch to argon2id
+type SHA1Hasher struct {
+	salt string
+}
+
+func NewSHA1Hasher(salt string) *SHA1Hasher {
+	return &SHA1Hasher{salt: salt}
+}
+
+func (h *SHA1Hasher) Hash(password string) (string, error) {
+	hash := sha1.New()
+
+	if _, err := hash.Write([]byte(password)); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
+}
+
+// Verify - checks the password, a valid hash is always reported for rehash
+func (h *SHA1Hasher) Verify(password, hash string) (ok, rehash bool, err error) {
+	actual, err := h.Hash(password)
+	if err != nil {
+		return false, false, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(hash)) != 1 {
+		return false, false, nil
+	}
+
+	return true, true, nil
+}
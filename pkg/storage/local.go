@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalStore - stores blobs as files under the root directory
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create root directory: %v", err)
+	}
+
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Put(key string, r io.Reader, size int64) error {
+	filePath := s.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %v", err)
+	}
+
+	// A short blob would be taken for the whole content by its readers
+	if written != size {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: wrote %d bytes of %d", written, size)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close blob: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to move blob: %v", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (s *LocalStore) GetRange(key string, offset, length int64) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &readCloser{
+		Reader: io.NewSectionReader(file, offset, length),
+		Closer: file,
+	}, nil
+}
+
+func (s *LocalStore) Stat(key string) (*BlobInfo, error) {
+	info, err := os.Stat(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &BlobInfo{
+		Key:     key,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Walk(fn func(info *BlobInfo) error) error {
+	return filepath.WalkDir(s.root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// The file is deleted while walking
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		key, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+
+		return fn(&BlobInfo{
+			Key:     filepath.ToSlash(key),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	})
+}
+
+// path - converts a key into a file path, keys can't escape the root directory
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+key)))
+}
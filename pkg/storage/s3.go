@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Store - stores blobs as objects in an S3-compatible bucket
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %v", err)
+	}
+
+	ctx := context.Background()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket: %v", err)
+	}
+
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket: %v", err)
+		}
+	}
+
+	return &S3Store{
+		client: client,
+		bucket: cfg.Bucket,
+	}, nil
+}
+
+func (s *S3Store) Put(key string, r io.Reader, size int64) error {
+	if _, err := s.client.PutObject(context.Background(), s.bucket, key, r, size, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to put object: %v", err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	// GetObject is lazy, Stat makes sure the object actually exists
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, convertS3Error(err)
+	}
+
+	return object, nil
+}
+
+func (s *S3Store) GetRange(key string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, opts)
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, convertS3Error(err)
+	}
+
+	return object, nil
+}
+
+func (s *S3Store) Stat(key string) (*BlobInfo, error) {
+	info, err := s.client.StatObject(context.Background(), s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	return &BlobInfo{
+		Key:     key,
+		Size:    info.Size,
+		ModTime: info.LastModified,
+	}, nil
+}
+
+func (s *S3Store) Delete(key string) error {
+	if _, err := s.Stat(key); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return convertS3Error(err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) Walk(fn func(info *BlobInfo) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list objects: %v", object.Err)
+		}
+
+		if err := fn(&BlobInfo{
+			Key:     object.Key,
+			Size:    object.Size,
+			ModTime: object.LastModified,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertS3Error(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+// TestS3Store - runs against an S3-compatible server, e.g. MinIO of docker-compose:
+// S3_TEST_ENDPOINT=localhost:9000 S3_ACCESS_KEY=... S3_SECRET_KEY=... go test ./pkg/storage
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT isn't set")
+	}
+
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	if bucket == "" {
+		bucket = "documents-test"
+	}
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    bucket,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		UseSSL:    os.Getenv("S3_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects of every run are kept apart, the bucket may be shared
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatal(err)
+	}
+
+	testBlobStore(t, store, "test-"+hex.EncodeToString(suffix)+"/")
+}
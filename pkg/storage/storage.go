@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore - storage of file contents addressed by opaque keys
+type BlobStore interface {
+	Put(key string, r io.Reader, size int64) error
+	Get(key string) (io.ReadCloser, error)
+	// GetRange - reads length bytes from the offset, the range must be inside the blob
+	GetRange(key string, offset, length int64) (io.ReadCloser, error)
+	Stat(key string) (*BlobInfo, error)
+	Delete(key string) error
+	// Walk - calls fn for every blob in the storage, stops on the first error of fn
+	Walk(fn func(info *BlobInfo) error) error
+}
+
+type BlobInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// readCloser - reader of a part of the blob that closes the whole blob
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
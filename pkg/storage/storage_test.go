@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testBlobStore - checks the BlobStore contract, keys are created under the prefix and deleted afterwards
+func testBlobStore(t *testing.T, store BlobStore, prefix string) {
+	const content = "0123456789abcdef"
+
+	blobs := map[string]string{
+		prefix + "sha256/ab/abcdef": content,
+		prefix + "uploads/1/0-a":    "chunk",
+		prefix + "empty":            "",
+	}
+	for key, data := range blobs {
+		if err := store.Put(key, strings.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	t.Cleanup(func() {
+		for key := range blobs {
+			store.Delete(key)
+		}
+	})
+
+	key := prefix + "sha256/ab/abcdef"
+	missing := prefix + "missing"
+
+	t.Run("Get", func(t *testing.T) {
+		tests := []struct {
+			name string
+			key  string
+			want string
+			err  error
+		}{
+			{name: "stored", key: key, want: content},
+			{name: "empty", key: prefix + "empty", want: ""},
+			{name: "missing", key: missing, err: ErrNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := readBlob(store.Get(tt.key))
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Get(%q) error = %v, want %v", tt.key, err, tt.err)
+				}
+
+				if got != tt.want {
+					t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("GetRange", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			key    string
+			offset int64
+			length int64
+			want   string
+			err    error
+		}{
+			{name: "start", key: key, offset: 0, length: 4, want: "0123"},
+			{name: "middle", key: key, offset: 5, length: 3, want: "567"},
+			{name: "end", key: key, offset: 10, length: 6, want: "abcdef"},
+			{name: "whole", key: key, offset: 0, length: 16, want: content},
+			{name: "one byte", key: key, offset: 15, length: 1, want: "f"},
+			{name: "missing", key: missing, offset: 0, length: 1, err: ErrNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := readBlob(store.GetRange(tt.key, tt.offset, tt.length))
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("GetRange(%q, %v, %v) error = %v, want %v", tt.key, tt.offset, tt.length, err, tt.err)
+				}
+
+				if got != tt.want {
+					t.Errorf("GetRange(%q, %v, %v) = %q, want %q", tt.key, tt.offset, tt.length, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := store.Stat(key)
+		if err != nil {
+			t.Fatalf("Stat(%q) error = %v", key, err)
+		}
+
+		if info.Key != key || info.Size != int64(len(content)) || info.ModTime.IsZero() {
+			t.Errorf("Stat(%q) = %+v, want the key, size %v and modification time", key, info, len(content))
+		}
+
+		if _, err := store.Stat(missing); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat(%q) error = %v, want %v", missing, err, ErrNotFound)
+		}
+	})
+
+	t.Run("Put short content", func(t *testing.T) {
+		short := prefix + "short"
+		if err := store.Put(short, strings.NewReader("abc"), 5); err == nil {
+			store.Delete(short)
+			t.Fatal("Put() of 3 bytes with size 5 succeeded")
+		}
+
+		if _, err := store.Stat(short); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat() of the failed blob error = %v, want %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		var keys []string
+		if err := store.Walk(func(info *BlobInfo) error {
+			if strings.HasPrefix(info.Key, prefix) {
+				keys = append(keys, info.Key)
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+
+		want := make([]string, 0, len(blobs))
+		for key := range blobs {
+			want = append(want, key)
+		}
+
+		sort.Strings(keys)
+		sort.Strings(want)
+		if strings.Join(keys, ",") != strings.Join(want, ",") {
+			t.Errorf("Walk() keys = %v, want %v", keys, want)
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		if err := store.Walk(func(info *BlobInfo) error {
+			calls++
+			return stop
+		}); !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Walk() stopped after %v calls with %v, want 1 call and %v", calls, err, stop)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleted := prefix + "uploads/1/0-a"
+		if err := store.Delete(deleted); err != nil {
+			t.Fatalf("Delete(%q) error = %v", deleted, err)
+		}
+
+		if _, err := store.Stat(deleted); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat() of the deleted blob error = %v, want %v", err, ErrNotFound)
+		}
+
+		if err := store.Delete(deleted); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete() of the deleted blob error = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func readBlob(blob io.ReadCloser, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+
+	b, err := io.ReadAll(blob)
+
+	return string(b), err
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testBlobStore(t, store, "")
+}
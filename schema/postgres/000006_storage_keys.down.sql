@@ -0,0 +1,3 @@
+UPDATE documents
+SET file_path = './uploads/' || file_path
+WHERE file_path != '';
\ No newline at end of file
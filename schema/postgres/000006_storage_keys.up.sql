@@ -0,0 +1,3 @@
+UPDATE documents
+SET file_path = SUBSTRING(file_path FROM LENGTH('./uploads/') + 1)
+WHERE file_path LIKE './uploads/%';
\ No newline at end of file
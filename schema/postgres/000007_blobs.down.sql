@@ -0,0 +1 @@
+DROP TABLE blobs;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE blobs (
+    key VARCHAR(255) PRIMARY KEY,
+    ref_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+INSERT INTO blobs (key, ref_count)
+SELECT file_path, COUNT(*)
+FROM documents
+WHERE file_path != ''
+GROUP BY file_path;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE document_versions;
+
+ALTER TABLE documents DROP COLUMN version;
\ No newline at end of file
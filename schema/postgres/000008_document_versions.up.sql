@@ -0,0 +1,11 @@
+ALTER TABLE documents ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE document_versions (
+    document_id UUID REFERENCES documents(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    mime VARCHAR(64) NOT NULL,
+    file_path VARCHAR(255),
+    document_data TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (document_id, version)
+);
\ No newline at end of file
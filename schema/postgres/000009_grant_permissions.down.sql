@@ -0,0 +1,5 @@
+DROP FUNCTION document_permission;
+
+DROP FUNCTION permission_rank;
+
+ALTER TABLE access_grants DROP COLUMN permission;
\ No newline at end of file
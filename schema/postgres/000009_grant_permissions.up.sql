@@ -0,0 +1,36 @@
+ALTER TABLE access_grants ADD COLUMN permission VARCHAR(16) NOT NULL DEFAULT 'read'
+    CHECK (permission IN ('read', 'write', 'share'));
+
+UPDATE access_grants ag
+SET permission = 'share'
+FROM documents d
+WHERE d.id = ag.document_id AND d.user_id = ag.user_id;
+
+CREATE FUNCTION permission_rank(permission VARCHAR) RETURNS INTEGER
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT CASE permission
+        WHEN 'read' THEN 1
+        WHEN 'write' THEN 2
+        WHEN 'share' THEN 3
+        WHEN 'owner' THEN 4
+        ELSE 0
+    END
+$$;
+
+-- document_permission - rank of the user's effective permission on the document, 0 - no access
+CREATE FUNCTION document_permission(doc_id UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    SELECT CASE
+        WHEN d.user_id = uid THEN permission_rank('owner')
+        ELSE GREATEST(
+            CASE WHEN d.is_public THEN permission_rank('read') ELSE 0 END,
+            COALESCE((
+                SELECT MAX(permission_rank(ag.permission))
+                FROM access_grants ag
+                WHERE ag.document_id = d.id AND ag.user_id = uid
+            ), 0)
+        )
+    END
+    FROM documents d
+    WHERE d.id = doc_id
+$$;
\ No newline at end of file
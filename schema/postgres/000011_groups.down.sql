@@ -0,0 +1,24 @@
+CREATE OR REPLACE FUNCTION document_permission(doc_id UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    SELECT CASE
+        WHEN d.user_id = uid THEN permission_rank('owner')
+        ELSE GREATEST(
+            CASE WHEN d.is_public THEN permission_rank('read') ELSE 0 END,
+            COALESCE((
+                SELECT MAX(permission_rank(ag.permission))
+                FROM access_grants ag
+                WHERE ag.document_id = d.id AND ag.user_id = uid
+            ), 0)
+        )
+    END
+    FROM documents d
+    WHERE d.id = doc_id
+$$;
+
+DROP VIEW document_grants;
+
+DROP TABLE group_grants;
+
+DROP TABLE group_members;
+
+DROP TABLE groups;
\ No newline at end of file
@@ -0,0 +1,63 @@
+CREATE TABLE groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(64) NOT NULL UNIQUE,
+    owner_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE group_members (
+    group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (group_id, user_id)
+);
+
+CREATE INDEX group_members_user_id_idx ON group_members (user_id);
+
+CREATE TABLE group_grants (
+    document_id UUID REFERENCES documents(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read'
+        CHECK (permission IN ('read', 'write', 'share')),
+    UNIQUE (document_id, group_id)
+);
+
+-- document_grants - grants of users and groups, only one of login and group_name is set
+CREATE VIEW document_grants AS
+    SELECT
+        ag.document_id,
+        u.login,
+        '' AS group_name,
+        ag.permission
+    FROM access_grants ag
+    JOIN users u ON ag.user_id = u.id
+    UNION ALL
+    SELECT
+        gg.document_id,
+        '' AS login,
+        g.name AS group_name,
+        gg.permission
+    FROM group_grants gg
+    JOIN groups g ON gg.group_id = g.id;
+
+CREATE OR REPLACE FUNCTION document_permission(doc_id UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    SELECT CASE
+        WHEN d.user_id = uid THEN permission_rank('owner')
+        ELSE GREATEST(
+            CASE WHEN d.is_public THEN permission_rank('read') ELSE 0 END,
+            COALESCE((
+                SELECT MAX(permission_rank(ag.permission))
+                FROM access_grants ag
+                WHERE ag.document_id = d.id AND ag.user_id = uid
+            ), 0),
+            COALESCE((
+                SELECT MAX(permission_rank(gg.permission))
+                FROM group_grants gg
+                JOIN group_members gm ON gm.group_id = gg.group_id
+                WHERE gg.document_id = d.id AND gm.user_id = uid
+            ), 0)
+        )
+    END
+    FROM documents d
+    WHERE d.id = doc_id
+$$;
\ No newline at end of file
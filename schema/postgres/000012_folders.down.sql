@@ -0,0 +1,30 @@
+CREATE OR REPLACE FUNCTION document_permission(doc_id UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    SELECT CASE
+        WHEN d.user_id = uid THEN permission_rank('owner')
+        ELSE GREATEST(
+            CASE WHEN d.is_public THEN permission_rank('read') ELSE 0 END,
+            COALESCE((
+                SELECT MAX(permission_rank(ag.permission))
+                FROM access_grants ag
+                WHERE ag.document_id = d.id AND ag.user_id = uid
+            ), 0),
+            COALESCE((
+                SELECT MAX(permission_rank(gg.permission))
+                FROM group_grants gg
+                JOIN group_members gm ON gm.group_id = gg.group_id
+                WHERE gg.document_id = d.id AND gm.user_id = uid
+            ), 0)
+        )
+    END
+    FROM documents d
+    WHERE d.id = doc_id
+$$;
+
+DROP FUNCTION folder_permission;
+
+DROP TABLE folder_grants;
+
+ALTER TABLE documents DROP COLUMN folder_id;
+
+DROP TABLE folders;
\ No newline at end of file
@@ -0,0 +1,82 @@
+CREATE TABLE folders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    parent_id UUID REFERENCES folders(id),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX folders_parent_id_idx ON folders (parent_id);
+
+ALTER TABLE documents ADD COLUMN folder_id UUID REFERENCES folders(id);
+
+CREATE INDEX documents_folder_id_idx ON documents (folder_id);
+
+-- folder_grants - grants inherited by documents in the folder and its subfolders,
+-- only one of user_id and group_id is set
+CREATE TABLE folder_grants (
+    folder_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read'
+        CHECK (permission IN ('read', 'write', 'share')),
+    CHECK ((user_id IS NULL) != (group_id IS NULL))
+);
+
+CREATE UNIQUE INDEX folder_grants_user_idx ON folder_grants (folder_id, user_id) WHERE user_id IS NOT NULL;
+
+CREATE UNIQUE INDEX folder_grants_group_idx ON folder_grants (folder_id, group_id) WHERE group_id IS NOT NULL;
+
+-- folder_permission - rank of the user's permission on the folder, granted on it or on any of its ancestors
+CREATE FUNCTION folder_permission(fid UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    WITH RECURSIVE ancestors AS (
+        SELECT id, parent_id, user_id
+        FROM folders
+        WHERE id = fid
+        UNION ALL
+        SELECT f.id, f.parent_id, f.user_id
+        FROM folders f
+        JOIN ancestors a ON f.id = a.parent_id
+    )
+    SELECT GREATEST(
+        (SELECT CASE WHEN f.user_id = uid THEN permission_rank('owner') ELSE 0 END FROM folders f WHERE f.id = fid),
+        COALESCE((
+            SELECT MAX(permission_rank(fg.permission))
+            FROM ancestors a
+            JOIN folder_grants fg ON fg.folder_id = a.id
+            WHERE
+                fg.user_id = uid
+                OR fg.group_id IN (
+                    SELECT gm.group_id
+                    FROM group_members gm
+                    WHERE gm.user_id = uid
+                )
+        ), 0)
+    )
+$$;
+
+CREATE OR REPLACE FUNCTION document_permission(doc_id UUID, uid UUID) RETURNS INTEGER
+LANGUAGE SQL STABLE AS $$
+    SELECT CASE
+        WHEN d.user_id = uid THEN permission_rank('owner')
+        ELSE GREATEST(
+            CASE WHEN d.is_public THEN permission_rank('read') ELSE 0 END,
+            COALESCE((
+                SELECT MAX(permission_rank(ag.permission))
+                FROM access_grants ag
+                WHERE ag.document_id = d.id AND ag.user_id = uid
+            ), 0),
+            COALESCE((
+                SELECT MAX(permission_rank(gg.permission))
+                FROM group_grants gg
+                JOIN group_members gm ON gm.group_id = gg.group_id
+                WHERE gg.document_id = d.id AND gm.user_id = uid
+            ), 0),
+            CASE WHEN d.folder_id IS NULL THEN 0 ELSE folder_permission(d.folder_id, uid) END
+        )
+    END
+    FROM documents d
+    WHERE d.id = doc_id
+$$;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX documents_search_vector_idx;
+
+ALTER TABLE documents DROP COLUMN search_vector;
\ No newline at end of file
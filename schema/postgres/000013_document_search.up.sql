@@ -0,0 +1,6 @@
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A')
+    || setweight(to_tsvector('simple', COALESCE(document_data, '')), 'B')
+) STORED;
+
+CREATE INDEX documents_search_vector_idx ON documents USING GIN (search_vector);
\ No newline at end of file
@@ -0,0 +1,15 @@
+DROP INDEX documents_content_pending_idx;
+
+DROP INDEX documents_search_vector_idx;
+
+ALTER TABLE documents DROP COLUMN search_vector;
+
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A')
+    || setweight(to_tsvector('simple', COALESCE(document_data, '')), 'B')
+) STORED;
+
+CREATE INDEX documents_search_vector_idx ON documents USING GIN (search_vector);
+
+ALTER TABLE documents DROP COLUMN content_path;
+ALTER TABLE documents DROP COLUMN content_text;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- content_path - file path the content was extracted from, differs from file_path while the extraction is pending
+ALTER TABLE documents ADD COLUMN content_text TEXT;
+ALTER TABLE documents ADD COLUMN content_path VARCHAR(255);
+
+DROP INDEX documents_search_vector_idx;
+
+ALTER TABLE documents DROP COLUMN search_vector;
+
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A')
+    || setweight(to_tsvector('simple', COALESCE(document_data, '')), 'B')
+    || setweight(to_tsvector('simple', COALESCE(content_text, '')), 'C')
+) STORED;
+
+CREATE INDEX documents_search_vector_idx ON documents USING GIN (search_vector);
+
+CREATE INDEX documents_content_pending_idx ON documents (updated_at)
+WHERE is_file AND file_path != '' AND content_path IS DISTINCT FROM file_path;
\ No newline at end of file
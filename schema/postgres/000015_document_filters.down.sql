@@ -0,0 +1 @@
+DROP FUNCTION document_data_json(TEXT);
\ No newline at end of file
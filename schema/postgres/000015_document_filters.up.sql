@@ -0,0 +1,9 @@
+-- document_data_json - JSON data of the document, NULL if the data isn't valid JSON
+CREATE FUNCTION document_data_json(data TEXT) RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+    RETURN data::JSONB;
+EXCEPTION WHEN invalid_text_representation THEN
+    RETURN NULL;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX documents_folder_name_idx;
+DROP INDEX documents_user_created_idx;
\ No newline at end of file
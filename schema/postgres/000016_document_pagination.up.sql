@@ -0,0 +1,3 @@
+-- Keyset pagination of listings in the default order
+CREATE INDEX documents_user_created_idx ON documents (user_id, created_at, id);
+CREATE INDEX documents_folder_name_idx ON documents (folder_id, name, id);
\ No newline at end of file
@@ -0,0 +1,28 @@
+ALTER TABLE documents DROP COLUMN type;
+
+DROP TABLE json_schemas;
+
+DROP INDEX documents_search_vector_idx;
+
+ALTER TABLE documents DROP COLUMN search_vector;
+
+ALTER TABLE documents ALTER COLUMN document_data TYPE TEXT USING document_data::TEXT;
+
+ALTER TABLE document_versions ALTER COLUMN document_data TYPE TEXT USING document_data::TEXT;
+
+CREATE FUNCTION document_data_json(data TEXT) RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+    RETURN data::JSONB;
+EXCEPTION WHEN invalid_text_representation THEN
+    RETURN NULL;
+END;
+$$;
+
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A')
+    || setweight(to_tsvector('simple', COALESCE(document_data, '')), 'B')
+    || setweight(to_tsvector('simple', COALESCE(content_text, '')), 'C')
+) STORED;
+
+CREATE INDEX documents_search_vector_idx ON documents USING GIN (search_vector);
\ No newline at end of file
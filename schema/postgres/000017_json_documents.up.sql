@@ -0,0 +1,36 @@
+DROP INDEX documents_search_vector_idx;
+
+ALTER TABLE documents DROP COLUMN search_vector;
+
+-- Data that isn't valid JSON is kept as a JSON string
+ALTER TABLE documents ALTER COLUMN document_data TYPE JSONB USING (
+    CASE WHEN document_data = '' THEN NULL
+    ELSE COALESCE(document_data_json(document_data), to_jsonb(document_data)) END
+);
+
+ALTER TABLE document_versions ALTER COLUMN document_data TYPE JSONB USING (
+    CASE WHEN document_data = '' THEN NULL
+    ELSE COALESCE(document_data_json(document_data), to_jsonb(document_data)) END
+);
+
+DROP FUNCTION document_data_json(TEXT);
+
+ALTER TABLE documents ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A')
+    || setweight(jsonb_to_tsvector('simple', COALESCE(document_data, '{}'), '["string", "numeric"]'), 'B')
+    || setweight(to_tsvector('simple', COALESCE(content_text, '')), 'C')
+) STORED;
+
+CREATE INDEX documents_search_vector_idx ON documents USING GIN (search_vector);
+
+CREATE TABLE json_schemas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(64) NOT NULL UNIQUE,
+    schema JSONB NOT NULL,
+    owner_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- type - name of the JSON schema the document data is validated against
+ALTER TABLE documents ADD COLUMN type VARCHAR(64) REFERENCES json_schemas(name) ON UPDATE CASCADE;
\ No newline at end of file
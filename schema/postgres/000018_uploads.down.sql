@@ -0,0 +1,3 @@
+DROP TABLE upload_chunks;
+
+DROP TABLE uploads;
\ No newline at end of file
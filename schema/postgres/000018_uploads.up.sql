@@ -0,0 +1,21 @@
+CREATE TABLE uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    received BIGINT NOT NULL DEFAULT 0,
+    -- completing - the upload is being turned into a document, chunks can't be added
+    completing BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX uploads_expires_at_idx ON uploads (expires_at);
+
+CREATE TABLE upload_chunks (
+    upload_id UUID NOT NULL REFERENCES uploads(id) ON DELETE CASCADE,
+    start BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    PRIMARY KEY (upload_id, start)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE document_versions DROP COLUMN mime_mismatch;
+
+ALTER TABLE documents DROP COLUMN mime_mismatch;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- mime_mismatch - the mime passed by the client contradicts the file content, mime is the detected one then
+ALTER TABLE documents ADD COLUMN mime_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE document_versions ADD COLUMN mime_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
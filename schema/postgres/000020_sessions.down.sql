@@ -0,0 +1,10 @@
+-- hashes of access tokens can't be turned back into tokens, users sign in again
+DELETE FROM tokens;
+
+DROP INDEX tokens_session_id_idx;
+
+ALTER TABLE tokens DROP COLUMN session_id;
+
+DROP TABLE refresh_tokens;
+
+DROP TABLE sessions;
\ No newline at end of file
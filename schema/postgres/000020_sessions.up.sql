@@ -0,0 +1,29 @@
+-- sessions - sign ins of users, a session lives while its refresh token is rotated
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- refresh_tokens - refresh tokens issued to sessions, token_hash - SHA-256 of the token.
+-- Used tokens are kept to detect their reuse
+CREATE TABLE refresh_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
+
+-- session_id - session the access token is issued to, NULL for tokens issued before sessions
+ALTER TABLE tokens ADD COLUMN session_id UUID REFERENCES sessions(id) ON DELETE CASCADE;
+
+CREATE INDEX tokens_session_id_idx ON tokens (session_id);
+
+-- token - SHA-256 of the access token, access tokens with the session don't fit the column as is.
+-- Issued tokens are hashed in place and stay valid
+UPDATE tokens SET token = ENCODE(SHA256(CONVERT_TO(token, 'UTF8')), 'hex');
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE sessions DROP COLUMN ip;
+
+ALTER TABLE sessions DROP COLUMN user_agent;
\ No newline at end of file
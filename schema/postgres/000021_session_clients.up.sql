@@ -0,0 +1,4 @@
+-- user_agent, ip - client of the last sign in or refresh of the session
+ALTER TABLE sessions ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '';
+
+ALTER TABLE sessions ADD COLUMN ip VARCHAR(64) NOT NULL DEFAULT '';
\ No newline at end of file
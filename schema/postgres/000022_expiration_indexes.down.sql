@@ -0,0 +1,7 @@
+DROP INDEX blobs_released_idx;
+
+DROP INDEX refresh_tokens_used_at_idx;
+
+DROP INDEX tokens_expires_at_idx;
+
+DROP INDEX sessions_expires_at_idx;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- expires_at indexes - expired sessions and tokens are purged by the janitor
+CREATE INDEX sessions_expires_at_idx ON sessions (expires_at);
+
+CREATE INDEX tokens_expires_at_idx ON tokens (expires_at);
+
+CREATE INDEX refresh_tokens_used_at_idx ON refresh_tokens (used_at);
+
+-- blobs_released_idx - blobs without references, their files are deleted by the janitor
+CREATE INDEX blobs_released_idx ON blobs (key) WHERE ref_count <= 0;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- hashes of share tokens can't be turned back into tokens, the links are recreated
+DELETE FROM share_links;
+
+ALTER TABLE share_links RENAME COLUMN token_hash TO token;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- token_hash - SHA-256 of the share token, the token is shown once when the link is created.
+-- Issued tokens are hashed in place and stay valid
+UPDATE share_links SET token = ENCODE(SHA256(CONVERT_TO(token, 'UTF8')), 'hex');
+
+ALTER TABLE share_links RENAME COLUMN token TO token_hash;
\ No newline at end of file